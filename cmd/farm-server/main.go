@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/theanzy/farmsim/internal/crop"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/save"
+	"github.com/theanzy/farmsim/internal/server"
+	"github.com/theanzy/farmsim/internal/sim"
+	"github.com/theanzy/farmsim/internal/tileset"
+)
+
+// farm-server keeps a shared farm running without a window, players join it like any LAN host
+func main() {
+	addr := flag.String("addr", fmt.Sprintf(":%d", lan.DefaultPort), "address to accept LAN players on")
+	mapPath := flag.String("map", "./resources/map/0.tmj", "tiled map of the farm")
+	itemsFile := flag.String("items", "./resources/data/items.json", "item catalog")
+	cropsFile := flag.String("crops", "./resources/data/crops.json", "crop definitions")
+	fishFile := flag.String("fish", "./resources/data/fish.json", "fish species")
+	saveFile := flag.String("save", "./saves/server.json", "save file, loaded on start and written on every new day and on shutdown")
+	farmName := flag.String("name", "Shared farm", "farm name of a new save")
+	tickRate := flag.Int("tick", 20, "simulation ticks per second")
+	announceAddr := flag.String("announce", lan.DefaultBroadcastAddr, "where the farm is broadcast for LAN discovery, empty to disable")
+	soilDecay := flag.Int("soil-decay", sim.DefaultSoilDecayDays, "days digged soil without a crop takes to turn back to grass, 0 to disable")
+	flag.Parse()
+
+	tmd, err := tileset.ParseMap(*mapPath)
+	if err != nil {
+		log.Printf("could not read map %s: %v", *mapPath, err)
+		return
+	}
+	catalog, err := items.LoadCatalog(*itemsFile)
+	if err != nil {
+		log.Printf("could not read items %s: %v", *itemsFile, err)
+		return
+	}
+	crops, err := crop.Load(*cropsFile, catalog)
+	if err != nil {
+		log.Printf("could not read crops %s: %v", *cropsFile, err)
+		return
+	}
+	fishes, err := sim.LoadFishes(*fishFile, catalog)
+	if err != nil {
+		log.Printf("could not read fish %s: %v", *fishFile, err)
+		return
+	}
+	game := sim.NewGame(&tmd, crops, fishes, catalog)
+	game.Farm.SoilDecayDays = *soilDecay
+
+	var playtime float64 = 0
+	name := *farmName
+	if data, err := save.Read(*saveFile); err == nil {
+		game.Load(data)
+		playtime = data.Playtime
+		name = data.FarmName
+		log.Printf("loaded %s, %s", *saveFile, game.Date())
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("could not load %s: %v", *saveFile, err)
+		return
+	}
+	saveGame := func() {
+		data := game.Save()
+		data.FarmName = name
+		data.Playtime = playtime
+		if err := save.Write(*saveFile, data); err != nil {
+			log.Printf("save failed: %v", err)
+		}
+	}
+
+	host, err := lan.Listen(*addr)
+	if err != nil {
+		log.Printf("could not host on %s: %v", *addr, err)
+		return
+	}
+	defer host.Close()
+	srv := server.New(game, host)
+	log.Printf("hosting %s on %s", name, host.Addr())
+
+	var announcer *lan.Announcer
+	if *announceAddr != "" {
+		announcer, err = lan.NewAnnouncer(*announceAddr, 2*time.Second, srv.Announcement(name))
+		if err != nil {
+			log.Printf("could not announce on %s: %v", *announceAddr, err)
+		} else {
+			defer announcer.Close()
+		}
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	tick := time.Second / time.Duration(max(1, *tickRate))
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			playtime += tick.Seconds()
+			if srv.Tick(float32(tick.Seconds())) {
+				saveGame()
+			}
+			if announcer != nil {
+				announcer.SetInfo(srv.Announcement(name))
+			}
+		case sig := <-stop:
+			log.Printf("%s, saving %s", sig, *saveFile)
+			saveGame()
+			return
+		}
+	}
+}
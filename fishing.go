@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/entity"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+const (
+	// the casting strip plays once, 15 frames at 12 per second
+	castDuration float32 = 125
+	// seconds the player has to strike once a fish bites
+	biteTime float32 = 0.6
+	// seconds the catch is held up before the player can move again
+	caughtTime float32 = 0.8
+	// seconds a fishing message stays on screen
+	messageTime float32 = 2.5
+)
+
+// Fishing drives the fishing rod from the cast to the catch. The line waits in the water for
+// a bite, the player strikes with the use key while the fish bites and then holds it to reel.
+type Fishing struct {
+	// "", casting, waiting, bite, reeling, landed, done
+	State    string
+	Location string // pond or sea
+	// where the line is in the water
+	Cell  sim.Cell
+	timer float32
+	reel  sim.Reel
+	// shown on the HUD after a catch or a lost fish
+	Message      string
+	messageTimer float32
+	// Act sends the catch through the session like every other gain
+	Act func(use lan.ToolUse)
+}
+
+func (f *Fishing) Active() bool {
+	return f.State != ""
+}
+
+// CastPoint is where the line lands, one tile past the tool hit point
+func CastPoint(player *entity.Player) rl.Vector2 {
+	hp := player.ToolHitPoint()
+	if player.Flipped {
+		return rl.NewVector2(hp.X-float32(player.TileSize), hp.Y)
+	}
+	return rl.NewVector2(hp.X+float32(player.TileSize), hp.Y)
+}
+
+// Cast throws the line into the water of location at cell
+func (f *Fishing) Cast(location string, cell sim.Cell, player *entity.Player) {
+	f.State = "casting"
+	f.Location = location
+	f.Cell = cell
+	player.UseTool(castDuration)
+}
+
+// Update moves fishing forward by dt seconds. reeling is true while the use key is held and
+// cancel when it is released.
+func (f *Fishing) Update(dt float32, reeling bool, cancel bool, player *entity.Player, game *sim.Game) {
+	f.timer -= dt
+	switch f.State {
+	case "casting":
+		if player.ToolCounter > 0 {
+			return
+		}
+		f.wait(player, game)
+	case "waiting":
+		if cancel {
+			f.Stop(player)
+		} else if f.timer <= 0 {
+			if fish, ok := game.Bite(f.Location); ok {
+				f.State = "bite"
+				f.timer = biteTime
+				f.reel = sim.NewReel(fish, game.Farm.Rand)
+			} else {
+				f.wait(player, game)
+			}
+		}
+	case "bite":
+		if reeling {
+			f.State = "reeling"
+			player.SetPose("REELING")
+		} else if f.timer <= 0 {
+			// too slow, the bait is gone but the line stays in the water
+			f.wait(player, game)
+		}
+	case "reeling":
+		switch f.reel.Update(dt, reeling) {
+		case sim.Caught:
+			fish := f.reel.Fish
+			size := fish.RollSize(game.Farm.Rand)
+			quality := fish.Quality(size)
+			f.Act(lan.ToolUse{Action: lan.ActionFish, Cell: sim.CellPosition(f.Cell), Item: fish.ID, Quality: int(quality)})
+			name := fish.ID
+			if item, ok := game.Catalog.Item(fish.ID); ok {
+				name = item.Name
+			}
+			message := fmt.Sprintf("Caught a %.0f cm %s", size, name)
+			if quality != items.Normal {
+				message += fmt.Sprintf(" (%s)", quality)
+			}
+			f.show(message)
+			f.State = "landed"
+			f.timer = caughtTime
+			player.SetPose("CAUGHT")
+		case sim.Snapped:
+			f.show("The line snapped")
+			f.end(player)
+		case sim.Escaped:
+			f.show("The fish got away")
+			f.end(player)
+		}
+	case "landed":
+		if f.timer <= 0 {
+			f.end(player)
+		}
+	case "done":
+		if !reeling {
+			f.Stop(player)
+		}
+	}
+}
+
+// end lets go of the rod but keeps fishing until the use key is up, its release would cast again
+func (f *Fishing) end(player *entity.Player) {
+	f.State = "done"
+	player.SetPose("")
+}
+
+func (f *Fishing) wait(player *entity.Player, game *sim.Game) {
+	f.State = "waiting"
+	f.timer = 1.5 + game.Farm.Rand.Float32()*4
+	player.SetPose("WAITING")
+}
+
+func (f *Fishing) show(message string) {
+	f.Message = message
+	f.messageTimer = messageTime
+}
+
+// Stop pulls the line out of the water and lets the player move again
+func (f *Fishing) Stop(player *entity.Player) {
+	f.State = ""
+	f.timer = 0
+	player.SetPose("")
+}
+
+// UpdateMessage fades the last message out, it keeps running after fishing stopped
+func (f *Fishing) UpdateMessage(dt float32) {
+	f.messageTimer = max(f.messageTimer-dt, 0)
+	if f.messageTimer == 0 {
+		f.Message = ""
+	}
+}
+
+// Draw shows the alert over the player while a fish bites and the tension bar while reeling
+func (f *Fishing) Draw(player *entity.Player, offset rl.Vector2, uiAssets map[string]rl.Texture2D, fishImg rl.Texture2D, scale float32) {
+	center := rl.Vector2Subtract(player.Center(), offset)
+	tilesize := float32(player.TileSize)
+	// top left of the tile over the head
+	head := rl.NewVector2(center.X-tilesize*0.5, center.Y-player.Size.Y*0.25-tilesize*0.5)
+	switch f.State {
+	case "bite":
+		DrawTextureCenterV(uiAssets["expression_alerted"], head, tilesize, scale)
+	case "landed":
+		DrawTextureCenterV(fishImg, head, tilesize, scale)
+	case "reeling":
+		// the bar stands on the side away from the water
+		x := center.X + tilesize
+		if !player.Flipped {
+			x = center.X - tilesize - 24
+		}
+		bar := rl.NewRectangle(x, center.Y-80, 16, 160)
+		rl.DrawRectangleRec(bar, rl.NewColor(40, 40, 60, 200))
+		low, high := f.reel.Zone()
+		rl.DrawRectangleRec(rl.NewRectangle(bar.X, bar.Y+bar.Height*(1-high), bar.Width, bar.Height*(high-low)), rl.NewColor(80, 200, 90, 200))
+		tension := bar.Y + bar.Height*(1-f.reel.Tension)
+		rl.DrawRectangleRec(rl.NewRectangle(bar.X-4, tension-2, bar.Width+8, 4), rl.White)
+		rl.DrawRectangleLinesEx(bar, 2, rl.White)
+
+		progress := rl.NewRectangle(bar.X+bar.Width+4, bar.Y, 6, bar.Height)
+		rl.DrawRectangleRec(progress, rl.NewColor(40, 40, 60, 200))
+		rl.DrawRectangleRec(rl.NewRectangle(progress.X, progress.Y+progress.Height*(1-f.reel.Progress), progress.Width, progress.Height*f.reel.Progress), rl.Gold)
+	}
+}
+
+// DrawMessage shows the last fishing message centered above pos
+func (f *Fishing) DrawMessage(pos rl.Vector2) {
+	if f.Message == "" {
+		return
+	}
+	rl.DrawText(f.Message, int32(pos.X)-rl.MeasureText(f.Message, 24)/2, int32(pos.Y), 24, rl.White)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// InputVersion is bumped whenever Input changes, recordings of another version are rejected
+const InputVersion = 4
+
+// Input is what the local player did during one simulation step. Held keys are sampled
+// every frame, presses are kept until a step consumes them so none is lost or repeated
+// when a frame runs zero or several steps. Recordings store one Input per step.
+type Input struct {
+	Up    bool `json:"up,omitempty"`
+	Down  bool `json:"down,omitempty"`
+	Left  bool `json:"left,omitempty"`
+	Right bool `json:"right,omitempty"`
+	// C is held to charge the selected tool and uses the selected item once released
+	ChargeTool bool `json:"chargeTool,omitempty"`
+	// C released, Space and I
+	UseItem         bool `json:"useItem,omitempty"`
+	Interact        bool `json:"interact,omitempty"`
+	ToggleInventory bool `json:"toggleInventory,omitempty"`
+	// hotbar slot picked with the keys 1 to 0, counted from 1 so that 0 is none
+	Slot int `json:"slot,omitempty"`
+	// mouse wheel steps, rolling down moves to the next slot
+	Scroll int `json:"scroll,omitempty"`
+	// left mouse button pressed and the cursor position
+	Click bool       `json:"click,omitempty"`
+	Mouse rl.Vector2 `json:"mouse"`
+}
+
+// keys of the hotbar slots in order, 0 is the last slot
+var slotKeys = []int32{
+	rl.KeyOne, rl.KeyTwo, rl.KeyThree, rl.KeyFour, rl.KeyFive,
+	rl.KeySix, rl.KeySeven, rl.KeyEight, rl.KeyNine, rl.KeyZero,
+}
+
+// PollInput adds the keyboard and mouse state of this frame to the presses not consumed yet
+func PollInput(in Input) Input {
+	in.Up = rl.IsKeyDown(rl.KeyUp)
+	in.Down = rl.IsKeyDown(rl.KeyDown)
+	in.Left = rl.IsKeyDown(rl.KeyLeft)
+	in.Right = rl.IsKeyDown(rl.KeyRight)
+	in.ChargeTool = rl.IsKeyDown(rl.KeyC)
+	in.UseItem = in.UseItem || rl.IsKeyReleased(rl.KeyC)
+	in.Interact = in.Interact || rl.IsKeyPressed(rl.KeySpace)
+	in.ToggleInventory = in.ToggleInventory || rl.IsKeyPressed(rl.KeyI)
+	for i, key := range slotKeys {
+		if rl.IsKeyPressed(key) {
+			in.Slot = i + 1
+		}
+	}
+	if wheel := rl.GetMouseWheelMove(); wheel > 0 {
+		in.Scroll--
+	} else if wheel < 0 {
+		in.Scroll++
+	}
+	in.Click = in.Click || rl.IsMouseButtonPressed(rl.MouseButtonLeft)
+	in.Mouse = rl.GetMousePosition()
+	return in
+}
+
+// Consume clears the presses once a step handled them, held keys stay down
+func (in Input) Consume() Input {
+	return Input{
+		Up:         in.Up,
+		Down:       in.Down,
+		Left:       in.Left,
+		Right:      in.Right,
+		ChargeTool: in.ChargeTool,
+		Mouse:      in.Mouse,
+	}
+}
+
+func (in Input) Movement() rl.Vector2 {
+	var x, y float32
+	if in.Left {
+		x -= 1
+	}
+	if in.Right {
+		x += 1
+	}
+	if in.Up {
+		y -= 1
+	}
+	if in.Down {
+		y += 1
+	}
+	return rl.NewVector2(x, y)
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const DaysPerSeason = 28
+
+// Seasons in the order they come in a year
+var Seasons = []string{"spring", "summer", "fall", "winter"}
+
+type Date struct {
+	// starts at 1
+	Year   int
+	Season string
+	// day of the season, starts at 1
+	Day int
+}
+
+// DateOf turns the number of days slept since the farm was created into a date
+func DateOf(day int) Date {
+	day = max(day, 0)
+	seasonCount := day / DaysPerSeason
+	return Date{
+		Year:   seasonCount/len(Seasons) + 1,
+		Season: Seasons[seasonCount%len(Seasons)],
+		Day:    day%DaysPerSeason + 1,
+	}
+}
+
+func IsSeason(name string) bool {
+	return slices.Contains(Seasons, name)
+}
+
+// String formats the date for the HUD, e.g. "Spring 3, Year 1"
+func (d Date) String() string {
+	return fmt.Sprintf("%s %d, Year %d", strings.ToUpper(d.Season[0:1])+d.Season[1:], d.Day, d.Year)
+}
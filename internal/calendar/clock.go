@@ -0,0 +1,26 @@
+package calendar
+
+import "fmt"
+
+// Clock is the time of day in minutes after midnight, it goes past 24:00 until the day ends
+type Clock float64
+
+const (
+	// 6:00, when players wake up
+	DayStart Clock = 6 * 60
+	// 2:00 of the next morning, players still up pass out
+	DayEnd Clock = 26 * 60
+	// game minutes that pass every real second
+	MinutesPerSecond = 10.0 / 7
+)
+
+// String shows the clock in steps of ten minutes, e.g. "6:00" or "1:30" after midnight
+func (c Clock) String() string {
+	minutes := int(c) / 10 * 10
+	return fmt.Sprintf("%d:%02d", minutes/60%24, minutes%60)
+}
+
+// Hours returns the clock in hours after midnight
+func (c Clock) Hours() float32 {
+	return float32(c) / 60
+}
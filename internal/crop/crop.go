@@ -1,39 +1,133 @@
 package crop
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 
-	rl "github.com/gen2brain/raylib-go/raylib"
-	"github.com/theanzy/farmsim/internal/strip"
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/items"
 )
 
-func LoadCropAssets(dirpath string, crops []string) (map[string]strip.StripImg, error) {
+var ErrInvalidDefinition = errors.New("invalid crop definition")
+
+type Definition struct {
+	Name string `json:"name"`
+	// sprite strip in the crops directory, one sprite per growth stage
+	Sprite       string `json:"sprite"`
+	Frames       int    `json:"frames"`
+	DaysPerStage int    `json:"daysPerStage"`
+	// seasons the crop grows in, it dies when another season starts
+	Seasons []string `json:"seasons"`
+	// consecutive dry days before the crop withers
+	WitherDays int `json:"witherDays"`
+	// age the crop goes back to after a harvest, 0 removes the crop
+	RegrowAge int `json:"regrowAge,omitempty"`
+	// tileset id of the crop shown in the HUD
+	TileID int `json:"tileId"`
+	// item ids of the seed planted and of the harvest
+	Seed string `json:"seed"`
+	Crop string `json:"crop"`
+}
+
+// Stage returns the growth stage of a crop that was watered age days, capped at the last sprite
+func (d Definition) Stage(age int) int {
+	return min(age/d.DaysPerStage, d.Frames-1)
+}
+
+// IsGrown is true once the crop went through every stage
+func (d Definition) IsGrown(age int) bool {
+	return age >= d.Frames*d.DaysPerStage
+}
+
+func (d Definition) GrowsIn(season string) bool {
+	return slices.Contains(d.Seasons, season)
+}
+
+// Load reads the crop definitions in the order of the file, their seed and crop must be in catalog
+func Load(path string, catalog items.Catalog) ([]Definition, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defs := []Definition{}
+	if err := json.Unmarshal(buffer, &defs); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, d := range defs {
+		switch {
+		case d.Name == "" || names[d.Name]:
+			return nil, fmt.Errorf("%w: missing or duplicate name %q", ErrInvalidDefinition, d.Name)
+		case d.Frames <= 0 || d.DaysPerStage <= 0:
+			return nil, fmt.Errorf("%w: %s needs frames and daysPerStage", ErrInvalidDefinition, d.Name)
+		case len(d.Seasons) == 0:
+			return nil, fmt.Errorf("%w: %s needs seasons", ErrInvalidDefinition, d.Name)
+		case d.WitherDays <= 0:
+			return nil, fmt.Errorf("%w: %s needs witherDays", ErrInvalidDefinition, d.Name)
+		case d.RegrowAge < 0 || d.IsGrown(d.RegrowAge):
+			return nil, fmt.Errorf("%w: %s regrows to an age past its last stage", ErrInvalidDefinition, d.Name)
+		}
+		for _, season := range d.Seasons {
+			if !calendar.IsSeason(season) {
+				return nil, fmt.Errorf("%w: %s has unknown season %q", ErrInvalidDefinition, d.Name, season)
+			}
+		}
+		if _, ok := catalog.Item(d.Seed); !ok {
+			return nil, fmt.Errorf("%w: %s has unknown seed item %q", ErrInvalidDefinition, d.Name, d.Seed)
+		}
+		if _, ok := catalog.Item(d.Crop); !ok {
+			return nil, fmt.Errorf("%w: %s has unknown crop item %q", ErrInvalidDefinition, d.Name, d.Crop)
+		}
+		names[d.Name] = true
+	}
+	return defs, nil
+}
+
+type StripFile struct {
+	Path       string
+	StripCount int
+}
+
+// FindStrips looks up the sprite strip of every name in dirpath, the strip count is read from the file name
+func FindStrips(dirpath string, names []string) (map[string]StripFile, error) {
 	r := regexp.MustCompile(`[a-z](\d+)\.png`)
 
-	res := map[string]strip.StripImg{}
+	res := map[string]StripFile{}
 	err := filepath.Walk(dirpath, func(path string, info fs.FileInfo, err error) error {
 		if filepath.Ext(path) == ".png" {
 			ftokens := strings.Split(info.Name(), "_")
 			cropName := ftokens[0]
-			if slices.Contains(crops, cropName) {
+			if slices.Contains(names, cropName) {
 				s := r.FindStringSubmatch(info.Name())[1]
 				stripCount, err := strconv.ParseInt(s, 10, 64)
 				if err != nil {
 					return err
 				}
-				res[cropName] = strip.NewStripImg(rl.LoadTexture(path), int32(stripCount))
+				res[cropName] = StripFile{Path: path, StripCount: int(stripCount)}
 			}
 		}
 		return nil
 	})
 	if err != nil {
-		return map[string]strip.StripImg{}, err
+		return map[string]StripFile{}, err
 	}
 
 	return res, nil
 }
+
+// Strips returns the sprite strip of every crop in defs, found in dirpath
+func Strips(dirpath string, defs []Definition) map[string]StripFile {
+	res := map[string]StripFile{}
+	for _, d := range defs {
+		res[d.Name] = StripFile{Path: filepath.Join(dirpath, d.Sprite), StripCount: d.Frames}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"math/rand/v2"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+const (
+	// seconds a drop bounces out before it can be picked up
+	dropSettleTime float32 = 0.5
+	// pixels per second a drop is thrown out at and loses every second
+	dropThrowSpeed float32 = 240
+	dropFriction   float32 = 600
+	// pixels per second a drop flies to the player at
+	dropMagnetSpeed float32 = 420
+)
+
+// Drop is one item lying on the ground, it flies to a player who walks close enough
+type Drop struct {
+	Item    string
+	Quality items.Quality
+	Pos     rl.Vector2
+	img     rl.Texture2D
+	vel     rl.Vector2
+	settle  float32
+}
+
+// Drops holds every item lying on the ground
+type Drops struct {
+	Items []Drop
+	// drops start flying to the player within magnetRadius and are picked up within pickupRadius
+	magnetRadius float32
+	pickupRadius float32
+}
+
+func NewDrops(tilesize float32) Drops {
+	return Drops{
+		Items:        []Drop{},
+		magnetRadius: tilesize * 2,
+		pickupRadius: tilesize * 0.4,
+	}
+}
+
+// Spawn throws quantity drops of an item out of pos in random directions
+func (d *Drops) Spawn(item string, quality items.Quality, quantity int, img rl.Texture2D, pos rl.Vector2, rng *rand.Rand) {
+	for range quantity {
+		dir := rl.Vector2Normalize(rl.NewVector2(rng.Float32()*2-1, rng.Float32()*2-1))
+		speed := dropThrowSpeed * (0.5 + rng.Float32()*0.5)
+		d.Items = append(d.Items, Drop{
+			Item:    item,
+			Quality: quality,
+			Pos:     pos,
+			img:     img,
+			vel:     rl.Vector2Scale(dir, speed),
+			settle:  dropSettleTime,
+		})
+	}
+}
+
+// Place puts a drop that already settled at pos
+func (d *Drops) Place(item string, quality items.Quality, img rl.Texture2D, pos rl.Vector2) {
+	d.Items = append(d.Items, Drop{Item: item, Quality: quality, Pos: pos, img: img})
+}
+
+// Update moves the drops by dt seconds and returns the ones the player at target picked up
+func (d *Drops) Update(dt float32, target rl.Vector2) []Drop {
+	picked := []Drop{}
+	remaining := d.Items[:0]
+	for _, drop := range d.Items {
+		drop.settle = max(drop.settle-dt, 0)
+		dist := rl.Vector2Distance(drop.Pos, target)
+		if drop.settle == 0 && dist <= d.pickupRadius {
+			picked = append(picked, drop)
+			continue
+		}
+		if drop.settle == 0 && dist <= d.magnetRadius {
+			drop.vel = rl.Vector2Scale(rl.Vector2Normalize(rl.Vector2Subtract(target, drop.Pos)), dropMagnetSpeed)
+		} else if speed := rl.Vector2Length(drop.vel); speed > 0 {
+			drop.vel = rl.Vector2Scale(drop.vel, max(speed-dropFriction*dt, 0)/speed)
+		}
+		drop.Pos = rl.Vector2Add(drop.Pos, rl.Vector2Scale(drop.vel, dt))
+		remaining = append(remaining, drop)
+	}
+	d.Items = remaining
+	return picked
+}
+
+func (d *Drops) Draw(offset rl.Vector2, scale float32) {
+	for _, drop := range d.Items {
+		size := rl.NewVector2(float32(drop.img.Width)*scale, float32(drop.img.Height)*scale)
+		pos := rl.NewVector2(drop.Pos.X-size.X*0.5-offset.X, drop.Pos.Y-size.Y*0.5-offset.Y)
+		rl.DrawTextureEx(drop.img, pos, 0, scale, rl.White)
+	}
+}
@@ -9,7 +9,9 @@ import (
 )
 
 type Player struct {
-	Pos             rl.Vector2
+	Pos rl.Vector2
+	// position before the last Update, used to draw between two updates
+	PrevPos         rl.Vector2
 	HitAreaOffset   rl.Rectangle
 	AssetSize       rl.Vector2
 	TileSize        int
@@ -21,11 +23,46 @@ type Player struct {
 	StyleAnimations map[string]anim.StripAnimation
 	Flipped         bool
 	Tool            string
-	Tools           []string
 	ToolCounter     float32
+	// seconds the use key was held to charge the next swing
+	ToolCharge float32
+	// tired players walk slower
+	Tired bool
+	// anim state held while set, e.g. waiting for a bite, the player stands still meanwhile
+	Pose string
 }
 
-func NewPlayer(pos rl.Vector2, tilesize int, scale int, animStyles anim.AnimStyles, tools []string, style string) Player {
+// seconds the use key is held for every charge level
+const chargeStep = 0.5
+
+func NewPlayer(pos rl.Vector2, tilesize int, scale int, animStyles anim.AnimStyles, style string) Player {
+	assetSize, size := playerSize(animStyles, scale)
+
+	hitboxSize := assetSize.X * 0.4
+
+	hitRect := rl.NewRectangle(size.X/2-hitboxSize/2, size.Y/2-hitboxSize/2, hitboxSize, hitboxSize)
+
+	baseAnimations, styleAnimations, toolAnimations := newAnimations(animStyles, assetSize, style)
+
+	return Player{
+		Pos:             pos,
+		PrevPos:         pos,
+		HitAreaOffset:   hitRect,
+		AssetSize:       assetSize,
+		Size:            size,
+		TileSize:        tilesize,
+		AnimStyles:      animStyles,
+		AnimState:       "IDLE",
+		BaseAnimations:  baseAnimations,
+		ToolAnimations:  toolAnimations,
+		StyleAnimations: styleAnimations,
+		Flipped:         false,
+		Tool:            "",
+		ToolCounter:     0,
+	}
+}
+
+func playerSize(animStyles anim.AnimStyles, scale int) (rl.Vector2, rl.Vector2) {
 	playerImg := animStyles["IDLE"].Base
 	assetSize := rl.NewVector2(
 		float32(playerImg.Width)/float32(animStyles["IDLE"].StripCount),
@@ -35,11 +72,11 @@ func NewPlayer(pos rl.Vector2, tilesize int, scale int, animStyles anim.AnimStyl
 		float32(assetSize.X)*float32(scale),
 		float32(assetSize.Y)*float32(scale),
 	)
+	return assetSize, size
+}
 
-	hitboxSize := assetSize.X * 0.4
-
-	hitRect := rl.NewRectangle(size.X/2-hitboxSize/2, size.Y/2-hitboxSize/2, hitboxSize, hitboxSize)
-
+// newAnimations returns the base, hair style and tool animations of every anim state
+func newAnimations(animStyles anim.AnimStyles, assetSize rl.Vector2, style string) (map[string]anim.StripAnimation, map[string]anim.StripAnimation, map[string]anim.StripAnimation) {
 	baseAnimations := map[string]anim.StripAnimation{}
 	toolAnimations := map[string]anim.StripAnimation{}
 	styleAnimations := map[string]anim.StripAnimation{}
@@ -69,52 +106,79 @@ func NewPlayer(pos rl.Vector2, tilesize int, scale int, animStyles anim.AnimStyl
 			)
 		}
 	}
+	return baseAnimations, styleAnimations, toolAnimations
+}
 
-	return Player{
-		Pos:             pos,
-		HitAreaOffset:   hitRect,
-		AssetSize:       assetSize,
-		Size:            size,
-		TileSize:        tilesize,
-		AnimStyles:      animStyles,
-		AnimState:       "IDLE",
-		BaseAnimations:  baseAnimations,
-		ToolAnimations:  toolAnimations,
-		StyleAnimations: styleAnimations,
-		Flipped:         false,
-		Tool:            "water",
-		Tools:           tools,
-		ToolCounter:     0,
+// SetTool takes out the tool of the selected item, empty when it is not a tool. A charge built
+// up for the previous tool is lost.
+func (p *Player) SetTool(tool string) {
+	if p.Tool == tool {
+		return
 	}
+	p.Tool = tool
+	p.ToolCharge = 0
 }
 
-func (p *Player) SwitchTool() {
+func (p *Player) UseTool(duration float32) {
 	if p.ToolCounter > 0 {
 		return
 	}
-	idx := slices.Index(p.Tools, p.Tool)
-	idx += 1
-	if idx >= len(p.Tools) {
-		idx = 0
-	}
-	p.Tool = p.Tools[idx]
+	p.ToolCounter = duration
 }
 
-func (p *Player) UseTool(duration float32) {
+// ChargeTool builds up the next swing while the use key is held
+func (p *Player) ChargeTool(dt float32) {
 	if p.ToolCounter > 0 {
 		return
 	}
-	p.ToolCounter = duration
+	p.ToolCharge += dt
+}
+
+// ChargeLevel returns the level the swing is charged to, up to maxLevel
+func (p *Player) ChargeLevel(maxLevel int) int {
+	return min(int(p.ToolCharge/chargeStep), maxLevel)
+}
+
+// ReleaseCharge returns the charge level of the swing and starts the next one from zero
+func (p *Player) ReleaseCharge(maxLevel int) int {
+	level := p.ChargeLevel(maxLevel)
+	p.ToolCharge = 0
+	return level
+}
+
+// SetPose holds an anim state from its first frame, an empty state lets the player move again
+func (p *Player) SetPose(state string) {
+	if p.Pose == state {
+		return
+	}
+	p.Pose = state
+	for _, anims := range []map[string]anim.StripAnimation{p.BaseAnimations, p.StyleAnimations, p.ToolAnimations} {
+		if a, ok := anims[state]; ok {
+			a.Reset()
+			anims[state] = a
+		}
+	}
 }
 
 func (p *Player) Center() rl.Vector2 {
 	return rl.NewVector2(p.Pos.X+p.Size.X*0.5, p.Pos.Y+p.Size.Y*0.5)
 }
 
-func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos rl.Vector2) []rl.Rectangle, addFarmHole func(pos rl.Vector2)) {
+// Lerp returns the position alpha of the way from the previous update to the last one
+func (p *Player) Lerp(alpha float32) rl.Vector2 {
+	return rl.Vector2Lerp(p.PrevPos, p.Pos, alpha)
+}
+
+// Update moves the player and plays the tool animations, onDig is called when the shovel hits the ground
+func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos rl.Vector2) []rl.Rectangle, onDig func()) {
+	p.PrevPos = p.Pos
 	frameMovement := rl.Vector2Normalize(movement)
-	if p.ToolCounter == 0 {
-		p.Pos.X += frameMovement.X * dt * 150
+	speed := float32(150)
+	if p.Tired {
+		speed = 90
+	}
+	if p.ToolCounter == 0 && p.Pose == "" {
+		p.Pos.X += frameMovement.X * dt * speed
 		for _, obstacle := range getObstacles(p.Center()) {
 			hitbox := p.Hitbox(rl.NewVector2(0, 0))
 			if rl.CheckCollisionRecs(hitbox, obstacle) {
@@ -126,7 +190,7 @@ func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos r
 			}
 		}
 
-		p.Pos.Y += frameMovement.Y * dt * 150
+		p.Pos.Y += frameMovement.Y * dt * speed
 		for _, obstacle := range getObstacles(p.Center()) {
 			hitbox := p.Hitbox(rl.NewVector2(0, 0))
 			if rl.CheckCollisionRecs(hitbox, obstacle) {
@@ -149,7 +213,14 @@ func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos r
 			p.AnimState = "DIG"
 		case "axe":
 			p.AnimState = "AXE"
+		case "pickaxe":
+			p.AnimState = "MINING"
+		case "rod":
+			p.AnimState = "CASTING"
 		}
+	} else if p.Pose != "" {
+		p.ToolCounter = 0
+		p.AnimState = p.Pose
 	} else {
 		p.ToolCounter = 0
 		if movement.Y > 0 {
@@ -168,7 +239,7 @@ func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos r
 		}
 	}
 
-	isToolAnimState := slices.Contains([]string{"WATERING", "DIG", "AXE"}, p.AnimState)
+	isToolAnimState := slices.Contains([]string{"WATERING", "DIG", "AXE", "MINING", "CASTING"}, p.AnimState)
 
 	baseAnim := p.BaseAnimations[p.AnimState]
 	baseAnim.Update(dt)
@@ -188,7 +259,7 @@ func (p *Player) Update(dt float32, movement rl.Vector2, getObstacles func(pos r
 		toolAnim.Update(dt)
 		if p.ToolCounter <= 0 && isToolAnimState {
 			if p.AnimState == "DIG" {
-				addFarmHole(p.ToolHitPoint())
+				onDig()
 			}
 			toolAnim.Reset()
 		}
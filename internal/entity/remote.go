@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"github.com/theanzy/farmsim/internal/anim"
+	"github.com/theanzy/farmsim/internal/render"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// how far behind the newest snapshot remote players are rendered, in seconds.
+// Two send intervals keep a snapshot on both sides of the render time most of the time.
+const interpolationDelay = 0.1
+
+// keep about a second of snapshots
+const maxSnapshots = 20
+
+type Snapshot struct {
+	Time      float64
+	Pos       rl.Vector2
+	AnimState string
+	Flipped   bool
+	Tool      string
+}
+
+// RemotePlayer is a player controlled over the network. It is drawn slightly in the past,
+// interpolating between the snapshots received from its owner.
+type RemotePlayer struct {
+	ID              int
+	Pos             rl.Vector2
+	Size            rl.Vector2
+	TileSize        int
+	AnimState       string
+	Flipped         bool
+	Tool            string
+	BaseAnimations  map[string]anim.StripAnimation
+	ToolAnimations  map[string]anim.StripAnimation
+	StyleAnimations map[string]anim.StripAnimation
+	snapshots       []Snapshot
+	clock           float64
+}
+
+func NewRemotePlayer(id int, tilesize int, scale int, animStyles anim.AnimStyles, style string) RemotePlayer {
+	assetSize, size := playerSize(animStyles, scale)
+	baseAnimations, styleAnimations, toolAnimations := newAnimations(animStyles, assetSize, style)
+	return RemotePlayer{
+		ID:              id,
+		Size:            size,
+		TileSize:        tilesize,
+		AnimState:       "IDLE",
+		BaseAnimations:  baseAnimations,
+		ToolAnimations:  toolAnimations,
+		StyleAnimations: styleAnimations,
+		snapshots:       []Snapshot{},
+	}
+}
+
+// Push records a snapshot received now. The first one also places the player.
+func (p *RemotePlayer) Push(s Snapshot) {
+	s.Time = p.clock
+	if len(p.snapshots) == 0 {
+		p.Pos = s.Pos
+		p.AnimState = s.AnimState
+		p.Flipped = s.Flipped
+		p.Tool = s.Tool
+	}
+	p.snapshots = append(p.snapshots, s)
+	if len(p.snapshots) > maxSnapshots {
+		p.snapshots = p.snapshots[len(p.snapshots)-maxSnapshots:]
+	}
+}
+
+func (p *RemotePlayer) Update(dt float32) {
+	p.clock += float64(dt)
+	renderTime := p.clock - interpolationDelay
+
+	prevState := p.AnimState
+	if n := len(p.snapshots); n > 0 {
+		// drop snapshots that are older than the pair around the render time
+		for len(p.snapshots) >= 2 && p.snapshots[1].Time <= renderTime {
+			p.snapshots = p.snapshots[1:]
+		}
+		from := p.snapshots[0]
+		if len(p.snapshots) >= 2 && from.Time <= renderTime {
+			to := p.snapshots[1]
+			t := float32((renderTime - from.Time) / (to.Time - from.Time))
+			p.Pos = rl.Vector2Lerp(from.Pos, to.Pos, t)
+		} else {
+			p.Pos = from.Pos
+		}
+		// discrete state can not be blended, take it from the older snapshot
+		p.AnimState = from.AnimState
+		p.Flipped = from.Flipped
+		p.Tool = from.Tool
+	}
+
+	for _, anims := range []map[string]anim.StripAnimation{p.BaseAnimations, p.StyleAnimations, p.ToolAnimations} {
+		a, ok := anims[p.AnimState]
+		if !ok {
+			continue
+		}
+		if prevState != p.AnimState {
+			a.Reset()
+		}
+		a.Update(dt)
+		anims[p.AnimState] = a
+	}
+}
+
+func (p *RemotePlayer) Center() rl.Vector2 {
+	return rl.NewVector2(p.Pos.X+p.Size.X*0.5, p.Pos.Y+p.Size.Y*0.5)
+}
+
+func (p *RemotePlayer) Draw(offset rl.Vector2) {
+	destRect := rl.NewRectangle(p.Pos.X-offset.X, p.Pos.Y-offset.Y, p.Size.X, p.Size.Y)
+	for _, anims := range []map[string]anim.StripAnimation{p.BaseAnimations, p.StyleAnimations, p.ToolAnimations} {
+		if a, ok := anims[p.AnimState]; ok {
+			rl.DrawTexturePro(a.Image, a.SrcRect(p.Flipped), destRect, rl.NewVector2(0, 0), 0, rl.White)
+		}
+	}
+}
+
+// Sprite depth sorts the player by its feet, the same way as the local player
+func (p *RemotePlayer) Sprite() render.Sprite {
+	return render.Sprite{
+		Draw: func(offset rl.Vector2, drawRoof bool) {
+			p.Draw(offset)
+		},
+		Center: func() rl.Vector2 {
+			return rl.NewVector2(p.Center().X, p.Pos.Y+float32(p.TileSize)*0.5)
+		},
+	}
+}
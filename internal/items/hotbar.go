@@ -0,0 +1,100 @@
+package items
+
+import "slices"
+
+// HotbarSize is the number of hotbar slots, keys 1 to 9 and 0 select them
+const HotbarSize = 10
+
+// item types put on the hotbar by themselves when they come into the inventory
+var hotbarTypes = []string{"tool", "seed", "fertilizer", "sapling"}
+
+// Hotbar keeps stacks of the inventory at hand. A slot is freed once its stack runs out and a
+// tool slot always holds the best tier of that tool.
+type Hotbar struct {
+	Slots    [HotbarSize]Stack
+	Selected int
+}
+
+// Select picks slot, out of range slots are ignored
+func (h *Hotbar) Select(slot int) {
+	if slot >= 0 && slot < HotbarSize {
+		h.Selected = slot
+	}
+}
+
+// Scroll moves the selection by steps and wraps around at both ends
+func (h *Hotbar) Scroll(steps int) {
+	h.Selected = ((h.Selected+steps)%HotbarSize + HotbarSize) % HotbarSize
+}
+
+// Assign puts stack in slot, it leaves the slot it was in before
+func (h *Hotbar) Assign(slot int, stack Stack) {
+	if slot < 0 || slot >= HotbarSize || stack.ID == "" {
+		return
+	}
+	for i, s := range h.Slots {
+		if s == stack {
+			h.Slots[i] = Stack{}
+		}
+	}
+	h.Slots[slot] = stack
+}
+
+// Item returns the stack in slot, false when the slot is empty
+func (h *Hotbar) Item(inventory *Inventory, slot int) (InventoryItem, bool) {
+	s := h.Slots[slot]
+	if s.ID == "" {
+		return InventoryItem{}, false
+	}
+	item, ok := inventory.Stack(s.ID, s.Quality)
+	return item, ok && item.Quantity > 0
+}
+
+func (h *Hotbar) SelectedItem(inventory *Inventory) (InventoryItem, bool) {
+	return h.Item(inventory, h.Selected)
+}
+
+// Sync follows the inventory. Slots of stacks that ran out are freed, tool slots move to the best
+// tier owned and new tools, seeds and consumables fill the free slots in inventory order.
+func (h *Hotbar) Sync(inventory *Inventory) {
+	held := inventory.Items()
+	for i := range h.Slots {
+		item, ok := h.Item(inventory, i)
+		if !ok {
+			h.Slots[i] = Stack{}
+		} else if item.Type == "tool" {
+			h.Slots[i] = bestTool(held, item.Tool)
+		}
+	}
+	for _, item := range held {
+		if !slices.Contains(hotbarTypes, item.Type) || h.holds(inventory, item) {
+			continue
+		}
+		if i := slices.Index(h.Slots[:], Stack{}); i != -1 {
+			h.Slots[i] = Stack{ID: item.ID, Quality: item.Quality}
+		}
+	}
+}
+
+// holds is true when a slot has the stack of item, any tier counts for a tool
+func (h *Hotbar) holds(inventory *Inventory, item InventoryItem) bool {
+	for i, s := range h.Slots {
+		if s == (Stack{ID: item.ID, Quality: item.Quality}) {
+			return true
+		}
+		if other, ok := h.Item(inventory, i); ok && item.Type == "tool" && other.Type == "tool" && other.Tool == item.Tool {
+			return true
+		}
+	}
+	return false
+}
+
+func bestTool(held []InventoryItem, tool string) Stack {
+	best := InventoryItem{Item: Item{Tier: -1}}
+	for _, item := range held {
+		if item.Type == "tool" && item.Tool == tool && item.Tier > best.Tier {
+			best = item
+		}
+	}
+	return Stack{ID: best.ID, Quality: best.Quality}
+}
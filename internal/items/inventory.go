@@ -1,21 +1,19 @@
 package items
 
 import (
-	"fmt"
-	"math"
 	"slices"
-	"strings"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// InventoryItem is a stack of items of one quality, its SellPrice already accounts for the quality
 type InventoryItem struct {
 	Item
+	Quality  Quality
 	Quantity int
 }
 
 type Inventory struct {
 	items   []InventoryItem
+	catalog []Item
 	deposit float32
 }
 
@@ -23,29 +21,67 @@ func NewInventory(items []Item) Inventory {
 	iItems := []InventoryItem{}
 	for _, item := range items {
 		q := 0
-		if item.Name == "Wheat seed" {
+		switch {
+		case item.ID == "wheat_seed":
 			q = 5
+		// every player owns the basic tools
+		case item.Type == "tool" && item.Tier == 0:
+			q = 1
 		}
 		iItems = append(iItems, InventoryItem{Item: item, Quantity: q})
 	}
-	return Inventory{items: iItems}
+	return Inventory{items: iItems, catalog: items}
 }
 
-func (i *Inventory) Increase(name string, quantity int) {
-	idx := slices.IndexFunc(i.items, func(x InventoryItem) bool {
-		return x.Name == name
+func (i *Inventory) index(id string, quality Quality) int {
+	return slices.IndexFunc(i.items, func(x InventoryItem) bool {
+		return x.ID == id && x.Quality == quality
 	})
-	if idx != -1 {
+}
+
+// Increase adds items of normal quality
+func (i *Inventory) Increase(id string, quantity int) {
+	i.Add(id, Normal, quantity)
+}
+
+// Add puts items in the stack of their quality, a new stack goes next to the other qualities of the item
+func (i *Inventory) Add(id string, quality Quality, quantity int) {
+	if idx := i.index(id, quality); idx != -1 {
 		item := i.items[idx]
 		item.Quantity += quantity
 		i.items[idx] = item
+		return
+	}
+	ci := slices.IndexFunc(i.catalog, func(x Item) bool {
+		return x.ID == id
+	})
+	if ci == -1 {
+		return
+	}
+	// keep the stacks of an item ordered by quality
+	pos := len(i.items)
+	for idx, item := range i.items {
+		if item.ID != id {
+			continue
+		}
+		if item.Quality < quality {
+			pos = idx + 1
+		} else if pos == len(i.items) {
+			pos = idx
+		}
 	}
+	stack := InventoryItem{Item: i.catalog[ci].WithQuality(quality), Quality: quality, Quantity: quantity}
+	i.items = slices.Insert(i.items, pos, stack)
 }
 
-func (i *Inventory) Decrease(name string, quantity int) int {
-	idx := slices.IndexFunc(i.items, func(x InventoryItem) bool {
-		return x.Name == name
-	})
+// Decrease removes items of normal quality
+func (i *Inventory) Decrease(id string, quantity int) int {
+	return i.Remove(id, Normal, quantity)
+}
+
+// Remove takes items from the stack of their quality and returns what is left, -1 when there is no such stack
+func (i *Inventory) Remove(id string, quality Quality, quantity int) int {
+	idx := i.index(id, quality)
 	if idx != -1 {
 		item := i.items[idx]
 		item.Quantity -= quantity
@@ -58,123 +94,60 @@ func (i *Inventory) Decrease(name string, quantity int) int {
 	return -1
 }
 
-func (i *Inventory) AvailableSeeds() []string {
-	res := []string{}
-	for _, item := range i.items {
-		if item.Quantity > 0 && item.Type == "seed" {
-			res = append(res, strings.ToLower(strings.Split(item.Name, " ")[0]))
-		}
-	}
-	return res
+// Item returns the stack of normal quality
+func (i *Inventory) Item(id string) (InventoryItem, bool) {
+	return i.Stack(id, Normal)
 }
 
-func (i *Inventory) Count(name string) int {
-	result := 0
-	for _, item := range i.items {
-		if item.Name == name {
-			result = item.Quantity
-		}
+func (i *Inventory) Stack(id string, quality Quality) (InventoryItem, bool) {
+	idx := i.index(id, quality)
+	if idx == -1 {
+		return InventoryItem{}, false
 	}
-	return result
+	return i.items[idx], true
 }
 
-func (i *Inventory) Items() []InventoryItem {
-	res := []InventoryItem{}
-	for _, item := range i.items {
-		if item.Quantity > 0 {
-			res = append(res, item)
-		}
+// Count returns how many items of normal quality there are
+func (i *Inventory) Count(id string) int {
+	if item, ok := i.Stack(id, Normal); ok {
+		return item.Quantity
 	}
-	return res
+	return 0
 }
 
-type InventoryUI struct {
-	container   rl.Rectangle
-	padding     float32
-	slotsize    float32
-	colcount    float32
-	InventoryId string
-	hoverId     string
+func (i *Inventory) Deposit() float32 {
+	return i.deposit
 }
 
-func NewInventoryUI(screenWidth float32, screenHeight float32, tilesize float32) InventoryUI {
-	var w float32 = 800.0
-	var h float32 = 600.0
-	container := rl.NewRectangle(screenWidth*0.5-w*0.5, screenHeight*0.5-h*0.5, w, h)
-	const padding float32 = 28.0
-	slotsize := tilesize
-	colcount := float32(math.Floor(float64(container.Width / (slotsize + padding))))
-	return InventoryUI{
-		container:   container,
-		padding:     padding,
-		slotsize:    slotsize,
-		colcount:    colcount,
-		InventoryId: "",
-	}
-
+// Withdraw takes up to amount from the deposit and returns what was taken
+func (i *Inventory) Withdraw(amount float32) float32 {
+	amount = min(max(amount, 0), i.deposit)
+	i.deposit -= amount
+	return amount
 }
 
-func (ui *InventoryUI) ItemClick(inventory *Inventory, mpos rl.Vector2) {
-	for i, item := range inventory.Items() {
-		irect := itemSlotRect(ui.container, i, ui.padding, ui.slotsize, ui.colcount)
-		if rl.CheckCollisionPointRec(mpos, irect) {
-			ui.InventoryId = item.Name
-		}
+// Restore overwrites every stack quantity and the deposit. Stacks missing from quantities are emptied.
+func (i *Inventory) Restore(quantities map[Stack]int, deposit float32) {
+	for idx, item := range i.items {
+		item.Quantity = 0
+		i.items[idx] = item
 	}
-}
-
-func (ui *InventoryUI) ItemHover(inventory *Inventory, mpos rl.Vector2) {
-	hovered := false
-	for i, item := range inventory.Items() {
-		irect := itemSlotRect(ui.container, i, ui.padding, ui.slotsize, ui.colcount)
-		if rl.CheckCollisionPointRec(mpos, irect) && item.Name != ui.InventoryId {
-			hovered = true
-			ui.hoverId = item.Name
+	for stack, quantity := range quantities {
+		if idx := i.index(stack.ID, stack.Quality); idx != -1 {
+			i.items[idx].Quantity = quantity
+		} else {
+			i.Add(stack.ID, stack.Quality, quantity)
 		}
 	}
-	if !hovered {
-		ui.hoverId = ""
-	}
+	i.deposit = deposit
 }
 
-func (ui *InventoryUI) Draw(inventory *Inventory, uiAssets map[string]rl.Texture2D, tilescale float32) {
-	lineColor := rl.NewColor(rl.Beige.R-20, rl.Beige.G-20, rl.Beige.B-20, 255)
-	rl.DrawRectangleRec(ui.container, rl.Beige)
-	rl.DrawRectangleLinesEx(ui.container, 2, lineColor)
-	rl.DrawText("Inventory", int32(ui.container.X)+20, int32(ui.container.Y)+10, 30, rl.White)
-	items := inventory.Items()
-	inventoryIdx := -1
-	padding := ui.padding
-	imgScale := tilescale
-	for i, item := range items {
-		rect := itemSlotRect(ui.container, i, padding, ui.slotsize, ui.colcount)
-		DrawItem(rect, item.Image, imgScale, item.Quantity)
-		if ui.InventoryId == item.Name {
-			inventoryIdx = i
-			drawSlotSelection(rect, tilescale, uiAssets, 255)
-		} else if ui.hoverId == item.Name {
-			drawSlotSelection(rect, tilescale, uiAssets, 100)
+func (i *Inventory) Items() []InventoryItem {
+	res := []InventoryItem{}
+	for _, item := range i.items {
+		if item.Quantity > 0 {
+			res = append(res, item)
 		}
 	}
-	if inventoryIdx >= 0 {
-
-		// name
-		descRect := rl.NewRectangle(ui.container.X+padding, ui.container.Y+ui.container.Height-padding-180, ui.container.Width-padding*2, 180)
-		rl.DrawRectangleRec(descRect, rl.White)
-		rl.DrawText(items[inventoryIdx].Name, int32(descRect.X+padding), int32(descRect.Y+padding*0.5), 25, rl.Black)
-
-		// price
-		priceText := fmt.Sprintf("$%d", items[inventoryIdx].SellPrice)
-		priceTextW := rl.MeasureText(priceText, 25)
-		rl.DrawText(priceText, int32(descRect.X+descRect.Width-padding-float32(priceTextW)), int32(descRect.Y+padding*0.5), 25, rl.DarkGray)
-
-		// description
-		DrawMultilineText(
-			items[inventoryIdx].Description,
-			rl.NewVector2(descRect.X+padding, descRect.Y+padding*2),
-			20,
-			int32(descRect.Width-6*padding),
-			8,
-		)
-	}
+	return res
 }
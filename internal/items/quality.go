@@ -0,0 +1,40 @@
+package items
+
+type Quality int
+
+const (
+	Normal Quality = iota
+	Silver
+	Gold
+)
+
+// sell price multiplier of every quality, in percent
+var qualityPrice = map[Quality]int{
+	Normal: 100,
+	Silver: 125,
+	Gold:   150,
+}
+
+func (q Quality) String() string {
+	switch q {
+	case Silver:
+		return "silver"
+	case Gold:
+		return "gold"
+	}
+	return "normal"
+}
+
+// WithQuality returns the item as sold at quality q
+func (item Item) WithQuality(q Quality) Item {
+	if p, ok := qualityPrice[q]; ok {
+		item.SellPrice = item.SellPrice * p / 100
+	}
+	return item
+}
+
+// Stack identifies the items of one id and quality in an inventory
+type Stack struct {
+	ID      string
+	Quality Quality
+}
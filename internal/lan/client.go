@@ -0,0 +1,74 @@
+package lan
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// Client is a connection to a host. Inbox is closed once the host goes away.
+type Client struct {
+	cn    *conn
+	inbox chan Message
+}
+
+func Dial(addr string, hello Hello) (*Client, error) {
+	c, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{
+		cn:    newConn(c),
+		inbox: make(chan Message, 256),
+	}
+	hello.Version = ProtocolVersion
+	m, err := NewMessage(MsgHello, hello)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	if err := client.Send(m); err != nil {
+		c.Close()
+		return nil, err
+	}
+	reply, err := client.cn.receive(handshakeTimeout)
+	if err == nil && reply.Type == MsgReject {
+		reject, _ := Decode[Reject](reply)
+		err = fmt.Errorf("%w: host has %d, client has %d", ErrVersionMismatch, reject.Version, ProtocolVersion)
+	} else if err == nil && reply.Type != MsgAccept {
+		err = fmt.Errorf("expected an accept, got %s", reply.Type)
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	go client.readLoop()
+	return client, nil
+}
+
+func (c *Client) readLoop() {
+	defer close(c.inbox)
+	err := c.cn.readLoop(func(m Message) {
+		// a game that stopped reading the inbox must not keep this goroutine after Close
+		select {
+		case c.inbox <- m:
+		case <-c.cn.closed:
+		}
+	})
+	if err != nil {
+		log.Printf("lan: host connection: %v", err)
+	}
+}
+
+func (c *Client) Inbox() <-chan Message {
+	return c.inbox
+}
+
+func (c *Client) Send(m Message) error {
+	return c.cn.send(m)
+}
+
+func (c *Client) Close() error {
+	return c.cn.close()
+}
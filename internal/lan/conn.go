@@ -0,0 +1,106 @@
+package lan
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// maximum size of one message, a full farm snapshot is well below this
+const maxMessageSize = 4 * 1024 * 1024
+
+const (
+	// messages waiting for a slow client before it is dropped
+	sendQueueSize = 256
+	writeTimeout  = 5 * time.Second
+	// time the other side has to say hello or to answer it
+	handshakeTimeout = 5 * time.Second
+)
+
+var ErrSendQueueFull = errors.New("send queue is full")
+
+type conn struct {
+	c       net.Conn
+	scanner *bufio.Scanner
+	writeMu sync.Mutex
+	enc     *json.Encoder
+	queue   chan Message
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newConn(c net.Conn) *conn {
+	scanner := bufio.NewScanner(c)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	return &conn{c: c, scanner: scanner, enc: json.NewEncoder(c), queue: make(chan Message, sendQueueSize), closed: make(chan struct{})}
+}
+
+func (c *conn) send(m Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(m)
+}
+
+// enqueue hands m to writeLoop without waiting for the network
+func (c *conn) enqueue(m Message) error {
+	select {
+	case c.queue <- m:
+		return nil
+	case <-c.closed:
+		return net.ErrClosed
+	default:
+		return ErrSendQueueFull
+	}
+}
+
+// writeLoop sends the queued messages until the connection is closed or a write fails
+func (c *conn) writeLoop() {
+	for {
+		select {
+		case m := <-c.queue:
+			if err := c.send(m); err != nil {
+				c.close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// receive waits for the next message, at most timeout
+func (c *conn) receive(timeout time.Duration) (Message, error) {
+	c.c.SetReadDeadline(time.Now().Add(timeout))
+	defer c.c.SetReadDeadline(time.Time{})
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return Message{}, err
+		}
+		return Message{}, io.EOF
+	}
+	var m Message
+	err := json.Unmarshal(c.scanner.Bytes(), &m)
+	return m, err
+}
+
+// readLoop calls handle for every message until the connection fails
+func (c *conn) readLoop(handle func(Message)) error {
+	for c.scanner.Scan() {
+		var m Message
+		if err := json.Unmarshal(c.scanner.Bytes(), &m); err != nil {
+			return err
+		}
+		handle(m)
+	}
+	return c.scanner.Err()
+}
+
+func (c *conn) close() error {
+	c.once.Do(func() { close(c.closed) })
+	return c.c.Close()
+}
@@ -0,0 +1,181 @@
+package lan
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DefaultDiscoveryPort = 7778
+
+// DefaultBroadcastAddr reaches every host on the local subnet, use 127.0.0.1 to stay on loopback
+var DefaultBroadcastAddr = net.JoinHostPort("255.255.255.255", strconv.Itoa(DefaultDiscoveryPort))
+
+// follows ProtocolVersion so games never list hosts they cannot talk to
+var announceMagic = "farm-lan/" + strconv.Itoa(ProtocolVersion)
+
+// Announcement is what a host broadcasts about its farm
+type Announcement struct {
+	Magic    string `json:"magic"`
+	FarmName string `json:"farmName"`
+	Day      int    `json:"day"`
+	Players  int    `json:"players"`
+	Port     int    `json:"port"`
+}
+
+// Announcer periodically broadcasts an Announcement until closed
+type Announcer struct {
+	conn   net.PacketConn
+	target *net.UDPAddr
+	mu     sync.Mutex
+	info   Announcement
+	done   chan struct{}
+}
+
+func NewAnnouncer(target string, interval time.Duration, info Announcement) (*Announcer, error) {
+	addr, err := net.ResolveUDPAddr("udp4", target)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	a := &Announcer{
+		conn:   conn,
+		target: addr,
+		info:   info,
+		done:   make(chan struct{}),
+	}
+	go a.loop(interval)
+	return a, nil
+}
+
+func (a *Announcer) SetInfo(info Announcement) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.info = info
+}
+
+func (a *Announcer) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.announce()
+		select {
+		case <-ticker.C:
+		case <-a.done:
+			return
+		}
+	}
+}
+
+func (a *Announcer) announce() {
+	a.mu.Lock()
+	info := a.info
+	a.mu.Unlock()
+	info.Magic = announceMagic
+	buffer, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	if _, err := a.conn.WriteTo(buffer, a.target); err != nil {
+		log.Printf("lan: announce failed: %v", err)
+	}
+}
+
+func (a *Announcer) Close() error {
+	close(a.done)
+	return a.conn.Close()
+}
+
+type HostEntry struct {
+	Announcement
+	// Addr is the TCP address to pass to Dial
+	Addr     string
+	LastSeen time.Time
+}
+
+// Browser collects the hosts announcing themselves on the discovery port
+type Browser struct {
+	conn  net.PacketConn
+	ttl   time.Duration
+	mu    sync.Mutex
+	hosts map[string]HostEntry
+}
+
+// Browse listens for announcements on addr, hosts silent for longer than ttl are dropped
+func Browse(addr string, ttl time.Duration) (*Browser, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	b := &Browser{
+		conn:  conn,
+		ttl:   ttl,
+		hosts: map[string]HostEntry{},
+	}
+	go b.loop()
+	return b, nil
+}
+
+func (b *Browser) Addr() net.Addr {
+	return b.conn.LocalAddr()
+}
+
+func (b *Browser) loop() {
+	buffer := make([]byte, 2048)
+	for {
+		n, from, err := b.conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		var info Announcement
+		if err := json.Unmarshal(buffer[:n], &info); err != nil || info.Magic != announceMagic {
+			continue
+		}
+		udpAddr, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		hostAddr := net.JoinHostPort(udpAddr.IP.String(), strconv.Itoa(info.Port))
+		b.mu.Lock()
+		b.hosts[hostAddr] = HostEntry{Announcement: info, Addr: hostAddr, LastSeen: time.Now()}
+		b.mu.Unlock()
+	}
+}
+
+// Hosts returns the hosts seen within the ttl, sorted by farm name
+func (b *Browser) Hosts() []HostEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := []HostEntry{}
+	for addr, h := range b.hosts {
+		if time.Since(h.LastSeen) > b.ttl {
+			delete(b.hosts, addr)
+			continue
+		}
+		res = append(res, h)
+	}
+	slices.SortFunc(res, func(a HostEntry, b HostEntry) int {
+		if a.FarmName == b.FarmName {
+			if a.Addr < b.Addr {
+				return -1
+			}
+			return 1
+		}
+		if a.FarmName < b.FarmName {
+			return -1
+		}
+		return 1
+	})
+	return res
+}
+
+func (b *Browser) Close() error {
+	return b.conn.Close()
+}
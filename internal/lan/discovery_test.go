@@ -0,0 +1,48 @@
+package lan
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDiscoveryOnLoopback(t *testing.T) {
+	b, err := Browse("127.0.0.1:0", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// a host of another protocol version is never listed
+	other, err := net.Dial("udp4", b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	stale, _ := json.Marshal(Announcement{Magic: "farm-lan/1", FarmName: "Old farm", Port: 1234})
+	if _, err := other.Write(stale); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Announcement{FarmName: "Sunny acres", Day: 12, Players: 3, Port: 7777}
+	a, err := NewAnnouncer(b.Addr().String(), 10*time.Millisecond, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(b.Hosts()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	hosts := b.Hosts()
+	if len(hosts) != 1 {
+		t.Fatalf("browser lists %+v, want one host", hosts)
+	}
+	got := hosts[0]
+	want.Magic = announceMagic
+	if got.Announcement != want || got.Addr != "127.0.0.1:7777" {
+		t.Errorf("browser lists %+v at %s, want %+v", got.Announcement, got.Addr, want)
+	}
+}
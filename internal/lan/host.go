@@ -0,0 +1,187 @@
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+)
+
+var ErrUnknownClient = errors.New("unknown client")
+var ErrVersionMismatch = errors.New("host and client speak different protocol versions")
+
+type Incoming struct {
+	ClientID int
+	Message  Message
+}
+
+// Host accepts LAN clients. Messages from every client end up in Inbox, a disconnect is
+// reported as a MsgPlayerLeave from that client. Every client has its own writer so a client
+// that stops reading cannot stall Send or Broadcast, it is dropped once its queue overflows.
+type Host struct {
+	listener net.Listener
+	mu       sync.Mutex
+	clients  map[int]*conn
+	nextID   int
+	inbox    chan Incoming
+	done     chan struct{}
+}
+
+func Listen(addr string) (*Host, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	h := &Host{
+		listener: l,
+		clients:  map[int]*conn{},
+		nextID:   HostPlayerID + 1,
+		inbox:    make(chan Incoming, 256),
+		done:     make(chan struct{}),
+	}
+	go h.acceptLoop()
+	return h, nil
+}
+
+func (h *Host) Addr() net.Addr {
+	return h.listener.Addr()
+}
+
+func (h *Host) Inbox() <-chan Incoming {
+	return h.inbox
+}
+
+func (h *Host) PlayerCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// count the host player too
+	return len(h.clients) + 1
+}
+
+func (h *Host) ClientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+func (h *Host) acceptLoop() {
+	for {
+		c, err := h.listener.Accept()
+		if err != nil {
+			select {
+			case <-h.done:
+			default:
+				log.Printf("lan: accept failed: %v", err)
+			}
+			return
+		}
+		go h.handshake(newConn(c))
+	}
+}
+
+// handshake admits a client whose hello has the protocol version of the host, a client of
+// another version is sent a MsgReject and disconnected
+func (h *Host) handshake(cn *conn) {
+	m, err := cn.receive(handshakeTimeout)
+	if err == nil && m.Type != MsgHello {
+		err = fmt.Errorf("expected a hello, got %s", m.Type)
+	}
+	var hello Hello
+	if err == nil {
+		hello, err = Decode[Hello](m)
+	}
+	if err == nil && hello.Version != ProtocolVersion {
+		if reject, err := NewMessage(MsgReject, Reject{Version: ProtocolVersion}); err == nil {
+			cn.send(reject)
+		}
+		err = fmt.Errorf("%w: client has %d, host has %d", ErrVersionMismatch, hello.Version, ProtocolVersion)
+	}
+	if err == nil {
+		err = cn.send(Message{Type: MsgAccept})
+	}
+	if err != nil {
+		log.Printf("lan: %s refused: %v", cn.c.RemoteAddr(), err)
+		cn.close()
+		return
+	}
+	h.mu.Lock()
+	id := h.nextID
+	h.nextID += 1
+	h.clients[id] = cn
+	h.mu.Unlock()
+	go cn.writeLoop()
+	h.push(Incoming{ClientID: id, Message: m})
+	h.serve(id, cn)
+}
+
+func (h *Host) serve(id int, cn *conn) {
+	err := cn.readLoop(func(m Message) {
+		h.push(Incoming{ClientID: id, Message: m})
+	})
+	if err != nil {
+		log.Printf("lan: client %d: %v", id, err)
+	}
+	h.mu.Lock()
+	delete(h.clients, id)
+	h.mu.Unlock()
+	cn.close()
+	if leave, err := NewMessage(MsgPlayerLeave, PlayerLeave{PlayerID: id}); err == nil {
+		h.push(Incoming{ClientID: id, Message: leave})
+	}
+}
+
+func (h *Host) push(in Incoming) {
+	select {
+	case h.inbox <- in:
+	case <-h.done:
+	}
+}
+
+func (h *Host) Send(clientID int, m Message) error {
+	h.mu.Lock()
+	cn, ok := h.clients[clientID]
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownClient, clientID)
+	}
+	return h.enqueue(clientID, cn, m)
+}
+
+// enqueue drops the client when its queue is full, serve then reports it as gone
+func (h *Host) enqueue(clientID int, cn *conn, m Message) error {
+	err := cn.enqueue(m)
+	if errors.Is(err, ErrSendQueueFull) {
+		log.Printf("lan: client %d is not reading, dropping it", clientID)
+		cn.close()
+	}
+	return err
+}
+
+// Broadcast sends m to every client except the one with id except, pass HostPlayerID to reach everyone
+func (h *Host) Broadcast(m Message, except int) {
+	h.mu.Lock()
+	targets := map[int]*conn{}
+	for id, cn := range h.clients {
+		if id != except {
+			targets[id] = cn
+		}
+	}
+	h.mu.Unlock()
+	for id, cn := range targets {
+		if err := h.enqueue(id, cn, m); err != nil {
+			log.Printf("lan: send to client %d: %v", id, err)
+		}
+	}
+}
+
+func (h *Host) Close() error {
+	close(h.done)
+	err := h.listener.Close()
+	h.mu.Lock()
+	for _, cn := range h.clients {
+		cn.close()
+	}
+	h.mu.Unlock()
+	return err
+}
@@ -0,0 +1,177 @@
+package lan
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/theanzy/farmsim/internal/save"
+)
+
+func listen(t *testing.T) *Host {
+	t.Helper()
+	h, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func receive(t *testing.T, inbox <-chan Incoming, want MessageType) Incoming {
+	t.Helper()
+	for {
+		select {
+		case in := <-inbox:
+			if in.Message.Type == want {
+				return in
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s arrived", want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	h := listen(t)
+	client, err := Dial(h.Addr().String(), Hello{Name: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	hello := receive(t, h.Inbox(), MsgHello)
+
+	use := ToolUse{Action: ActionPlant, Cell: save.Position{X: 3, Y: 4}, Crop: "wheat"}
+	m, _ := NewMessage(MsgToolUse, use)
+	if err := client.Send(m); err != nil {
+		t.Fatal(err)
+	}
+	in := receive(t, h.Inbox(), MsgToolUse)
+	if got, err := Decode[ToolUse](in.Message); err != nil || got != use || in.ClientID != hello.ClientID {
+		t.Errorf("host got %+v from %d, %v", got, in.ClientID, err)
+	}
+
+	update := FarmUpdate{FarmTiles: []save.FarmTile{{Pos: save.Position{X: 3, Y: 4}, State: "planted", CropAge: 1}}}
+	m, _ = NewMessage(MsgFarmUpdate, update)
+	h.Broadcast(m, HostPlayerID)
+	select {
+	case m := <-client.Inbox():
+		got, err := Decode[FarmUpdate](m)
+		if err != nil || m.Type != MsgFarmUpdate || len(got.FarmTiles) != 1 || got.FarmTiles[0] != update.FarmTiles[0] {
+			t.Errorf("client got %s %+v, %v", m.Type, got, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no farm update arrived")
+	}
+}
+
+// dialRaw connects to addr and says hello with version, without any of the client logic
+func dialRaw(t *testing.T, addr string, version int) net.Conn {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	hello, _ := NewMessage(MsgHello, Hello{Version: version, Name: "raw"})
+	if err := json.NewEncoder(c).Encode(hello); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSlowClientIsDropped(t *testing.T) {
+	h := listen(t)
+	// a client that says hello and never reads
+	dialRaw(t, h.Addr().String(), ProtocolVersion)
+	for h.ClientCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	big := FarmUpdate{FarmTiles: make([]save.FarmTile, 1000)}
+	m, _ := NewMessage(MsgFarmUpdate, big)
+	start := time.Now()
+	for range 4 * sendQueueSize {
+		h.Broadcast(m, HostPlayerID)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("broadcasting to a stuck client took %s", d)
+	}
+	receive(t, h.Inbox(), MsgPlayerLeave)
+}
+
+func TestHostRejectsAnotherVersion(t *testing.T) {
+	h := listen(t)
+	c := dialRaw(t, h.Addr().String(), ProtocolVersion-1)
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var reply Message
+	if err := json.NewDecoder(c).Decode(&reply); err != nil || reply.Type != MsgReject {
+		t.Fatalf("host answered %s, %v", reply.Type, err)
+	}
+	if reject, err := Decode[Reject](reply); err != nil || reject.Version != ProtocolVersion {
+		t.Errorf("reject gave %+v, %v", reject, err)
+	}
+	if n := h.ClientCount(); n != 0 {
+		t.Errorf("%d clients joined", n)
+	}
+}
+
+func TestDialFailsOnAnotherVersion(t *testing.T) {
+	// a host of another version that rejects every hello
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		reject, _ := NewMessage(MsgReject, Reject{Version: ProtocolVersion + 1})
+		json.NewEncoder(c).Encode(reject)
+	}()
+	if _, err := Dial(l.Addr().String(), Hello{Name: "tester"}); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("dial gave %v", err)
+	}
+}
+
+func TestClosedClientStopsReading(t *testing.T) {
+	h := listen(t)
+	client, err := Dial(h.Addr().String(), Hello{Name: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, h.Inbox(), MsgHello)
+	// fill the inbox of a game that stopped reading it
+	m, _ := NewMessage(MsgPlayerLeave, PlayerLeave{PlayerID: 7})
+	for i := range cap(client.inbox) + 10 {
+		h.Broadcast(m, HostPlayerID)
+		// stay below the send queue of the host
+		if i%50 == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	client.Close()
+	// the read loop has to give up on its pending message by itself
+	time.Sleep(100 * time.Millisecond)
+	n := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-client.Inbox():
+			if !ok {
+				if n > cap(client.inbox) {
+					t.Errorf("%d messages arrived after Close", n-cap(client.inbox))
+				}
+				return
+			}
+			n += 1
+		case <-timeout:
+			t.Fatal("the read loop is still running after Close")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package lan
+
+import (
+	"encoding/json"
+
+	"github.com/theanzy/farmsim/internal/save"
+)
+
+const DefaultPort = 7777
+
+// ProtocolVersion is bumped with every change to the messages below
+const ProtocolVersion = 10
+
+// HostPlayerID is the id of the player running the host, clients get ids starting at 1
+const HostPlayerID = 0
+
+type MessageType string
+
+const (
+	MsgHello           MessageType = "hello"
+	MsgWelcome         MessageType = "welcome"
+	MsgPlayerMove      MessageType = "player_move"
+	MsgPlayerLeave     MessageType = "player_leave"
+	MsgToolUse         MessageType = "tool_use"
+	MsgFarmUpdate      MessageType = "farm_update"
+	MsgInventoryChange MessageType = "inventory_change"
+	MsgDayUpdate       MessageType = "day_update"
+	MsgSleep           MessageType = "sleep"
+	// the host answers a hello with one of these before anything else
+	MsgAccept MessageType = "accept"
+	MsgReject MessageType = "reject"
+)
+
+const (
+	ActionDig       = "dig"
+	ActionWater     = "water"
+	ActionAxe       = "axe"
+	ActionPickaxe   = "pickaxe"
+	ActionPlant     = "plant"
+	ActionHarvest   = "harvest"
+	ActionFertilize = "fertilize"
+	ActionPlantTree = "plant_tree"
+	ActionFish      = "fish"
+)
+
+// Message is the envelope sent over the wire, one JSON object per line
+type Message struct {
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func NewMessage(t MessageType, payload any) (Message, error) {
+	buffer, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Type: t, Payload: buffer}, nil
+}
+
+func Decode[T any](m Message) (T, error) {
+	var res T
+	err := json.Unmarshal(m.Payload, &res)
+	return res, err
+}
+
+// client -> host, first message after connecting
+type Hello struct {
+	// ProtocolVersion of the client, Dial fills it in
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Style   string `json:"style"`
+}
+
+// host -> client, the hello was refused because the client speaks another protocol version
+type Reject struct {
+	Version int `json:"version"`
+}
+
+// host -> client, reply to Hello with the full farm state
+type Welcome struct {
+	PlayerID  int             `json:"playerId"`
+	Day       int             `json:"day"`
+	Clock     float64         `json:"clock"`
+	Weather   string          `json:"weather"`
+	Forecast  string          `json:"forecast"`
+	FarmTiles []save.FarmTile `json:"farmTiles"`
+	Trees     []save.Tree     `json:"trees"`
+	Rocks     []save.Rock     `json:"rocks"`
+}
+
+// both ways, the host relays it to every other client
+type PlayerMove struct {
+	PlayerID  int     `json:"playerId"`
+	X         float32 `json:"x"`
+	Y         float32 `json:"y"`
+	AnimState string  `json:"animState"`
+	Flipped   bool    `json:"flipped"`
+	Tool      string  `json:"tool"`
+	Style     string  `json:"style"`
+}
+
+type PlayerLeave struct {
+	PlayerID int `json:"playerId"`
+}
+
+// client -> host, the host validates it against its own farm. It does not know the inventory of
+// clients and trusts them to own the seed, fertilizer or sapling they use.
+type ToolUse struct {
+	PlayerID int           `json:"playerId"`
+	Action   string        `json:"action"`
+	Cell     save.Position `json:"cell"`
+	Crop     string        `json:"crop,omitempty"`
+	// item id of the fertilizer or sapling used, or of the fish caught
+	Item string `json:"item,omitempty"`
+	// quality of the fish caught, its size is rolled by the player who reeled it in
+	Quality int `json:"quality,omitempty"`
+}
+
+// host -> clients, only the tiles, trees and rocks that changed
+type FarmUpdate struct {
+	FarmTiles []save.FarmTile `json:"farmTiles"`
+	Trees     []save.Tree     `json:"trees"`
+	Rocks     []save.Rock     `json:"rocks"`
+}
+
+// host -> client, result of a tool use that gave or took items
+type InventoryChange struct {
+	// item id
+	Item string `json:"item"`
+	// items.Quality of the stack, 0 is normal
+	Quality int `json:"quality,omitempty"`
+	Delta   int `json:"delta"`
+	// cell of the tool use, items gained there drop on the ground
+	Cell save.Position `json:"cell"`
+}
+
+type DayUpdate struct {
+	Day      int    `json:"day"`
+	Weather  string `json:"weather"`
+	Forecast string `json:"forecast"`
+	// the day ended because the clock ran out, every player pays the penalty
+	PassedOut bool `json:"passedOut,omitempty"`
+}
+
+// client -> host, a player went to bed. A dedicated server starts the next day once every
+// player is in bed, a hosting player ends the day by sleeping themselves.
+type Sleep struct {
+	PlayerID int `json:"playerId"`
+}
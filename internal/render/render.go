@@ -9,6 +9,7 @@ import (
 type DepthRenderer struct {
 	counter      int
 	sortInterval int
+	nextID       int
 	Sprites      []Sprite
 }
 
@@ -20,6 +21,20 @@ func NewDepthRenderer(sortInverval int) DepthRenderer {
 	}
 }
 
+// Add registers a sprite that may later be removed with the returned id
+func (r *DepthRenderer) Add(sprite Sprite) int {
+	r.nextID += 1
+	sprite.ID = r.nextID
+	r.Sprites = append(r.Sprites, sprite)
+	return sprite.ID
+}
+
+func (r *DepthRenderer) Remove(id int) {
+	r.Sprites = slices.DeleteFunc(r.Sprites, func(s Sprite) bool {
+		return s.ID == id
+	})
+}
+
 func (r *DepthRenderer) Update() {
 	r.counter -= 1
 	if r.counter <= 0 {
@@ -37,6 +52,8 @@ func (r *DepthRenderer) Draw(offset rl.Vector2, drawRoof bool) {
 }
 
 type Sprite struct {
+	// set by Add, zero for sprites that live as long as the renderer
+	ID     int
 	Draw   func(offset rl.Vector2, drawRoof bool)
 	Center func() rl.Vector2
 }
@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/save"
+	"github.com/theanzy/farmsim/internal/server"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+// step is one frame of the recorded session
+type step struct {
+	Use   lan.ToolUse `json:"use"`
+	Sleep bool        `json:"sleep,omitempty"`
+}
+
+const stepVersion = 1
+
+func play(g *sim.Game, s step) {
+	if s.Sleep {
+		g.Sleep()
+		return
+	}
+	if change, err := server.ApplyToolUse(g, &g.Inventory, s.Use); err == nil {
+		sim.Change{Item: change.Item, Quality: items.Quality(change.Quality), Delta: change.Delta}.Apply(&g.Inventory)
+	}
+}
+
+// session digs, plants and waters a few tiles and sleeps through some nights
+func session(g *sim.Game) []step {
+	steps := []step{}
+	for cell := range g.Farm.Tiles {
+		if len(steps) == 12 {
+			break
+		}
+		pos := sim.CellPosition(cell)
+		steps = append(steps,
+			step{Use: lan.ToolUse{Action: lan.ActionDig, Cell: pos}},
+			step{Use: lan.ToolUse{Action: lan.ActionPlant, Cell: pos, Crop: "wheat"}},
+			step{Use: lan.ToolUse{Action: lan.ActionWater, Cell: pos}},
+		)
+	}
+	for range 5 {
+		steps = append(steps, step{Sleep: true})
+	}
+	return steps
+}
+
+func TestReplayGivesTheSameGame(t *testing.T) {
+	const seed = 42
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	g := sim.NewTestGame(t, seed)
+	g.Farm.SoilDecayDays = 2
+	g.Farm.WeedChance = 0.5
+	header := Header{Frames: stepVersion, Seed: seed, Start: g.Save(), SoilDecayDays: 2, WeedChance: 0.5}
+	r, err := Create[step](path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range session(g) {
+		if err := r.Write(s); err != nil {
+			t.Fatal(err)
+		}
+		play(g, s)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := g.Save()
+
+	playback, err := Open[step](path, stepVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = sim.NewTestGame(t, playback.Header.Seed)
+	g.Load(playback.Header.Start)
+	g.Farm.SoilDecayDays = playback.Header.SoilDecayDays
+	g.Farm.WeedChance = playback.Header.WeedChance
+	for s, ok := playback.Next(); ok; s, ok = playback.Next() {
+		play(g, s)
+	}
+	if got := g.Save(); !reflect.DeepEqual(got, want) {
+		t.Errorf("replay ended on\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestOpenRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.jsonl")
+	r, err := Create[step](path, Header{Frames: stepVersion, Start: save.Data{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err := Open[step](path, stepVersion+1); !errors.Is(err, ErrFrameVersion) {
+		t.Errorf("opening older frames gave %v", err)
+	}
+}
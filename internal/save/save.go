@@ -0,0 +1,156 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Version is bumped whenever the layout of Data changes in a way older
+// readers cannot understand.
+const Version = 2
+
+// oldest version Read still accepts, sim.Game.Load migrates what changed
+const minVersion = 1
+
+var ErrUnsupportedVersion = errors.New("unsupported save version")
+
+type Position struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type FarmTile struct {
+	Pos      Position `json:"pos"`
+	State    string   `json:"state"`
+	IsWet    bool     `json:"isWet"`
+	CropAge  int      `json:"cropAge"`
+	DryDays  int      `json:"dryDays,omitempty"`
+	Withered bool     `json:"withered,omitempty"`
+	// soil fertility and dry days of the current crop, they set the odds of a better harvest
+	Fertility  int `json:"fertility,omitempty"`
+	MissedDays int `json:"missedDays,omitempty"`
+	IdleDays   int `json:"idleDays,omitempty"`
+}
+
+type Tree struct {
+	Pos       Position `json:"pos"`
+	State     string   `json:"state"`
+	WoodCount int      `json:"woodCount"`
+	// saves without them only hold the trees of the map, each at full health
+	Kind   int `json:"kind,omitempty"`
+	Health int `json:"health,omitempty"`
+	Age    int `json:"age,omitempty"`
+}
+
+type Rock struct {
+	Pos        Position `json:"pos"`
+	State      string   `json:"state"`
+	StoneCount int      `json:"stoneCount"`
+}
+
+type ItemStack struct {
+	ID string `json:"id"`
+	// display name, version 1 saves identified items by it
+	Name string `json:"name,omitempty"`
+	// items.Quality of inventory stacks, 0 is normal
+	Quality  int `json:"quality,omitempty"`
+	Quantity int `json:"quantity"`
+}
+
+type Inventory struct {
+	Items   []ItemStack `json:"items"`
+	Deposit float32     `json:"deposit"`
+}
+
+// HotbarSlot is the stack a hotbar slot holds, an empty id is a free slot
+type HotbarSlot struct {
+	ID      string `json:"id,omitempty"`
+	Quality int    `json:"quality,omitempty"`
+}
+
+// Drop is one item on the ground at Pos in pixels
+type Drop struct {
+	ID      string   `json:"id"`
+	Quality int      `json:"quality,omitempty"`
+	Pos     Position `json:"pos"`
+}
+
+type Hotbar struct {
+	Slots    []HotbarSlot `json:"slots"`
+	Selected int          `json:"selected,omitempty"`
+}
+
+type Shop struct {
+	Name  string      `json:"name"`
+	Items []ItemStack `json:"items"`
+}
+
+type Data struct {
+	Version  int     `json:"version"`
+	FarmName string  `json:"farmName"`
+	Playtime float64 `json:"playtime"`
+	Day      int     `json:"day"`
+	// minutes after midnight
+	Clock float64 `json:"clock,omitempty"`
+	// energy the player used today, saves without it start with full energy
+	EnergyUsed float32 `json:"energyUsed,omitempty"`
+	// charges poured out of the watering can, saves without it start with a full can
+	WaterUsed int        `json:"waterUsed,omitempty"`
+	Weather   string     `json:"weather,omitempty"`
+	Forecast  string     `json:"forecast,omitempty"`
+	PlayerPos Position   `json:"playerPos"`
+	FarmTiles []FarmTile `json:"farmTiles"`
+	Trees     []Tree     `json:"trees"`
+	// saves without rocks keep every rock whole
+	Rocks     []Rock    `json:"rocks,omitempty"`
+	Inventory Inventory `json:"inventory"`
+	// saves without it fill the hotbar from the inventory
+	Hotbar Hotbar `json:"hotbar"`
+	Shops  []Shop `json:"shops"`
+	// items lying on the ground
+	Drops []Drop `json:"drops,omitempty"`
+}
+
+func Write(path string, data Data) error {
+	data.Version = Version
+	buffer, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, buffer)
+}
+
+func writeFile(path string, buffer []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// write to a temp file first so a crash mid-write never corrupts the previous save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buffer, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func Read(path string) (Data, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return Data{}, err
+	}
+	var data Data
+	if err := json.Unmarshal(buffer, &data); err != nil {
+		return Data{}, err
+	}
+	if data.Version < minVersion || data.Version > Version {
+		return Data{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, data.Version)
+	}
+	return data, nil
+}
+
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
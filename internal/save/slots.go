@@ -0,0 +1,173 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrSlotNotFound = errors.New("save slot not found")
+
+const slotPrefix = "slot"
+const slotExt = ".json"
+
+type SlotInfo struct {
+	ID       string
+	Path     string
+	FarmName string
+	Day      int
+	Money    float32
+	Playtime time.Duration
+	ModTime  time.Time
+}
+
+type Slots struct {
+	dir string
+}
+
+func NewSlots(dir string) Slots {
+	return Slots{dir: dir}
+}
+
+func (s Slots) Path(id string) string {
+	return filepath.Join(s.dir, id+slotExt)
+}
+
+// List returns every readable slot in the directory, most recently played first
+func (s Slots) List() ([]SlotInfo, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []SlotInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := []SlotInfo{}
+	for _, e := range entries {
+		id, ok := slotID(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := s.Info(id)
+		if err != nil {
+			// skip unreadable or incompatible files instead of hiding every other slot
+			continue
+		}
+		res = append(res, info)
+	}
+	slices.SortFunc(res, func(a SlotInfo, b SlotInfo) int {
+		return b.ModTime.Compare(a.ModTime)
+	})
+	return res, nil
+}
+
+func (s Slots) Info(id string) (SlotInfo, error) {
+	path := s.Path(id)
+	stat, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SlotInfo{}, fmt.Errorf("%w: %s", ErrSlotNotFound, id)
+	}
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	data, err := Read(path)
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	return SlotInfo{
+		ID:       id,
+		Path:     path,
+		FarmName: data.FarmName,
+		Day:      data.Day,
+		Money:    data.Inventory.Deposit,
+		Playtime: time.Duration(data.Playtime * float64(time.Second)),
+		ModTime:  stat.ModTime(),
+	}, nil
+}
+
+// Create reserves a new slot id. Nothing is written until the game saves into Path.
+func (s Slots) Create(farmName string) (SlotInfo, error) {
+	id, err := s.nextID()
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	return SlotInfo{
+		ID:       id,
+		Path:     s.Path(id),
+		FarmName: farmName,
+		ModTime:  time.Now(),
+	}, nil
+}
+
+func (s Slots) Duplicate(id string) (SlotInfo, error) {
+	data, err := Read(s.Path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return SlotInfo{}, fmt.Errorf("%w: %s", ErrSlotNotFound, id)
+	}
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	// the copy keeps the version and layout of the original, only its farm name changes
+	buffer, err := os.ReadFile(s.Path(id))
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(buffer, &fields); err != nil {
+		return SlotInfo{}, err
+	}
+	if fields["farmName"], err = json.Marshal(data.FarmName + " (copy)"); err != nil {
+		return SlotInfo{}, err
+	}
+	if buffer, err = json.MarshalIndent(fields, "", "  "); err != nil {
+		return SlotInfo{}, err
+	}
+	newID, err := s.nextID()
+	if err != nil {
+		return SlotInfo{}, err
+	}
+	if err := writeFile(s.Path(newID), buffer); err != nil {
+		return SlotInfo{}, err
+	}
+	return s.Info(newID)
+}
+
+func (s Slots) Delete(id string) error {
+	err := os.Remove(s.Path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrSlotNotFound, id)
+	}
+	return err
+}
+
+func (s Slots) nextID() (string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	next := 1
+	for _, e := range entries {
+		id, ok := slotID(e.Name())
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, slotPrefix)); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("%s%03d", slotPrefix, next), nil
+}
+
+// example: slot001.json -> slot001
+func slotID(filename string) (string, bool) {
+	if !strings.HasPrefix(filename, slotPrefix) || filepath.Ext(filename) != slotExt {
+		return "", false
+	}
+	return strings.TrimSuffix(filename, slotExt), true
+}
@@ -0,0 +1,33 @@
+package save
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDuplicateKeepsVersion1(t *testing.T) {
+	dir := t.TempDir()
+	// version 1 saves name their items instead of using ids
+	v1 := `{"version": 1, "farmName": "Old farm", "day": 3, "farmTiles": [], "trees": [],
+		"inventory": {"items": [{"name": "Wheat seed", "quantity": 4}], "deposit": 20}, "shops": []}`
+	if err := os.WriteFile(filepath.Join(dir, "1.json"), []byte(v1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	slots := NewSlots(dir)
+	info, err := slots.Duplicate("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FarmName != "Old farm (copy)" {
+		t.Errorf("copy is named %q", info.FarmName)
+	}
+	data, err := Read(info.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := data.Inventory.Items
+	if data.Version != 1 || data.Day != 3 || len(items) != 1 || items[0].Name != "Wheat seed" || items[0].Quantity != 4 {
+		t.Errorf("copy holds %+v", data)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/save"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+// ErrUnknownAction is returned for a tool use the rules do not know
+var ErrUnknownAction = errors.New("unknown action")
+
+// ApplyToolUse runs a tool action against the authoritative farm through the commands of game and
+// returns the items the actor gained or spent. inventory belongs to the actor, it is nil for LAN clients
+// whose inventory the host does not know, and is left alone.
+func ApplyToolUse(game *sim.Game, inventory *items.Inventory, use lan.ToolUse) (lan.InventoryChange, error) {
+	change, err := command(game, inventory, use)
+	if err != nil {
+		return lan.InventoryChange{}, err
+	}
+	return lan.InventoryChange{Item: change.Item, Quality: int(change.Quality), Delta: change.Delta, Cell: use.Cell}, nil
+}
+
+func command(game *sim.Game, inventory *items.Inventory, use lan.ToolUse) (sim.Change, error) {
+	cell := sim.PositionCell(use.Cell)
+	switch use.Action {
+	case lan.ActionDig:
+		return sim.Change{}, game.Dig(cell)
+	case lan.ActionWater:
+		return sim.Change{}, game.Water(cell)
+	case lan.ActionPlant:
+		return game.Plant(inventory, cell, use.Crop)
+	case lan.ActionHarvest:
+		return game.Harvest(cell)
+	case lan.ActionFertilize:
+		return game.Fertilize(inventory, cell, use.Item)
+	case lan.ActionAxe:
+		return game.Chop(cell)
+	case lan.ActionPlantTree:
+		return game.PlantTree(inventory, cell, use.Item)
+	case lan.ActionPickaxe:
+		return game.Mine(cell)
+	case lan.ActionFish:
+		return game.Catch(cell, use.Item, items.Quality(use.Quality))
+	}
+	return sim.Change{}, ErrUnknownAction
+}
+
+// FarmUpdate returns the tile, tree or rock touched by use
+func FarmUpdate(farm *sim.Farm, use lan.ToolUse) lan.FarmUpdate {
+	update := lan.FarmUpdate{FarmTiles: []save.FarmTile{}, Trees: []save.Tree{}, Rocks: []save.Rock{}}
+	cell := sim.PositionCell(use.Cell)
+	if use.Action == lan.ActionAxe || use.Action == lan.ActionPlantTree {
+		if idx := farm.TreeAt(cell); idx != -1 {
+			update.Trees = append(update.Trees, farm.SaveTree(farm.Trees[idx]))
+		}
+	} else if use.Action == lan.ActionPickaxe {
+		if idx := farm.RockAt(cell); idx != -1 {
+			update.Rocks = append(update.Rocks, farm.SaveRock(farm.Rocks[idx]))
+		}
+	} else if ft, ok := farm.Tiles[cell]; ok {
+		update.FarmTiles = append(update.FarmTiles, farm.SaveTile(ft))
+	}
+	return update
+}
+
+// NewDay returns the messages telling clients that the day changed along with the whole farm
+func NewDay(game *sim.Game, passedOut bool) []lan.Message {
+	res := []lan.Message{}
+	if m, err := lan.NewMessage(lan.MsgDayUpdate, lan.DayUpdate{Day: game.Day, Weather: game.Weather, Forecast: game.Forecast, PassedOut: passedOut}); err == nil {
+		res = append(res, m)
+	}
+	farmTiles, trees := game.Farm.Save()
+	if m, err := lan.NewMessage(lan.MsgFarmUpdate, lan.FarmUpdate{FarmTiles: farmTiles, Trees: trees, Rocks: game.Farm.SaveRocks()}); err == nil {
+		res = append(res, m)
+	}
+	return res
+}
+
+// Welcome is the reply to a client that said hello
+func Welcome(game *sim.Game, playerID int) lan.Welcome {
+	farmTiles, trees := game.Farm.Save()
+	return lan.Welcome{
+		PlayerID:  playerID,
+		Day:       game.Day,
+		Clock:     float64(game.Clock),
+		Weather:   game.Weather,
+		Forecast:  game.Forecast,
+		FarmTiles: farmTiles,
+		Trees:     trees,
+		Rocks:     game.Farm.SaveRocks(),
+	}
+}
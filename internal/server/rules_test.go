@@ -0,0 +1,39 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/save"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+// farmCell finds a tile that can be dug
+func farmCell(t *testing.T, g *sim.Game) save.Position {
+	t.Helper()
+	for cell := range g.Farm.Tiles {
+		return save.Position{X: float32(cell.X), Y: float32(cell.Y)}
+	}
+	t.Fatal("the map has no farm")
+	return save.Position{}
+}
+
+func TestApplyToolUse(t *testing.T) {
+	g := sim.NewTestGame(t, 1)
+	cell := farmCell(t, g)
+	if _, err := ApplyToolUse(g, &g.Inventory, lan.ToolUse{Action: lan.ActionDig, Cell: cell}); err != nil {
+		t.Fatal(err)
+	}
+	plant := lan.ToolUse{Action: lan.ActionPlant, Cell: cell, Crop: "carrot"}
+	if _, err := ApplyToolUse(g, &g.Inventory, plant); !errors.Is(err, sim.ErrNoSeed) {
+		t.Errorf("planting without seeds gave %v", err)
+	}
+	change, err := ApplyToolUse(g, nil, plant)
+	if err != nil || change.Item != "carrot_seed" || change.Delta != -1 || change.Cell != cell {
+		t.Errorf("a trusted client planting gave %+v, %v", change, err)
+	}
+	if _, err := ApplyToolUse(g, nil, lan.ToolUse{Action: "dance", Cell: cell}); !errors.Is(err, ErrUnknownAction) {
+		t.Errorf("an unknown action gave %v", err)
+	}
+}
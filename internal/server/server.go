@@ -0,0 +1,149 @@
+package server
+
+import (
+	"log"
+	"net"
+
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+// Server runs a farm for LAN clients without a local player. It owns the farm the same way
+// a hosting player does and relays player movement between clients.
+type Server struct {
+	Game     *sim.Game
+	host     *lan.Host
+	names    map[int]string
+	sleeping map[int]bool
+}
+
+func New(game *sim.Game, host *lan.Host) *Server {
+	return &Server{
+		Game:     game,
+		host:     host,
+		names:    map[int]string{},
+		sleeping: map[int]bool{},
+	}
+}
+
+// Tick handles every message received since the last tick and moves the clock forward by dt seconds
+// while players are connected. It returns true when a new day started.
+func (s *Server) Tick(dt float32) bool {
+	newDay := false
+	for {
+		select {
+		case in := <-s.host.Inbox():
+			if s.handle(in) {
+				newDay = true
+			}
+			continue
+		default:
+		}
+		if len(s.names) > 0 && s.Game.Tick(dt) {
+			s.passOut()
+			newDay = true
+		}
+		return newDay
+	}
+}
+
+func (s *Server) handle(in lan.Incoming) bool {
+	switch in.Message.Type {
+	case lan.MsgHello:
+		hello, err := lan.Decode[lan.Hello](in.Message)
+		if err != nil {
+			log.Printf("client %d: bad hello: %v", in.ClientID, err)
+			return false
+		}
+		log.Printf("%s joined as player %d", hello.Name, in.ClientID)
+		s.names[in.ClientID] = hello.Name
+		if m, err := lan.NewMessage(lan.MsgWelcome, Welcome(s.Game, in.ClientID)); err == nil {
+			s.host.Send(in.ClientID, m)
+		}
+	case lan.MsgPlayerMove:
+		move, err := lan.Decode[lan.PlayerMove](in.Message)
+		if err != nil {
+			return false
+		}
+		// never trust the id sent by the client
+		move.PlayerID = in.ClientID
+		if m, err := lan.NewMessage(lan.MsgPlayerMove, move); err == nil {
+			s.host.Broadcast(m, in.ClientID)
+		}
+	case lan.MsgPlayerLeave:
+		log.Printf("%s left", s.names[in.ClientID])
+		delete(s.names, in.ClientID)
+		delete(s.sleeping, in.ClientID)
+		if m, err := lan.NewMessage(lan.MsgPlayerLeave, lan.PlayerLeave{PlayerID: in.ClientID}); err == nil {
+			s.host.Broadcast(m, in.ClientID)
+		}
+		// the one who left may have been the last one awake
+		return s.trySleep()
+	case lan.MsgToolUse:
+		use, err := lan.Decode[lan.ToolUse](in.Message)
+		if err != nil {
+			return false
+		}
+		use.PlayerID = in.ClientID
+		// the server keeps no inventories and trusts clients to own what they use
+		change, err := ApplyToolUse(s.Game, nil, use)
+		if err != nil {
+			return false
+		}
+		if change.Item != "" {
+			if m, err := lan.NewMessage(lan.MsgInventoryChange, change); err == nil {
+				s.host.Send(in.ClientID, m)
+			}
+		}
+		if m, err := lan.NewMessage(lan.MsgFarmUpdate, FarmUpdate(&s.Game.Farm, use)); err == nil {
+			s.host.Broadcast(m, lan.HostPlayerID)
+		}
+	case lan.MsgSleep:
+		s.sleeping[in.ClientID] = true
+		return s.trySleep()
+	}
+	return false
+}
+
+// trySleep starts the next day once every connected player is in bed
+func (s *Server) trySleep() bool {
+	if len(s.names) == 0 {
+		return false
+	}
+	for id := range s.names {
+		if !s.sleeping[id] {
+			return false
+		}
+	}
+	s.sleeping = map[int]bool{}
+	s.Game.Sleep()
+	log.Printf("%s", s.Game.Date())
+	for _, m := range NewDay(s.Game, false) {
+		s.host.Broadcast(m, lan.HostPlayerID)
+	}
+	return true
+}
+
+// passOut ends the day for every player still up when the clock runs out
+func (s *Server) passOut() {
+	s.sleeping = map[int]bool{}
+	s.Game.Sleep()
+	log.Printf("%s, players passed out", s.Game.Date())
+	for _, m := range NewDay(s.Game, true) {
+		s.host.Broadcast(m, lan.HostPlayerID)
+	}
+}
+
+// Announcement describes the farm for LAN discovery, there is no host player to count
+func (s *Server) Announcement(farmName string) lan.Announcement {
+	port := lan.DefaultPort
+	if addr, ok := s.host.Addr().(*net.TCPAddr); ok {
+		port = addr.Port
+	}
+	return lan.Announcement{
+		FarmName: farmName,
+		Day:      s.Game.Day,
+		Players:  s.host.ClientCount(),
+		Port:     port,
+	}
+}
@@ -0,0 +1,104 @@
+package sfx
+
+import (
+	"math/rand/v2"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type particle struct {
+	pos   rl.Vector2
+	speed rl.Vector2
+	size  float32
+}
+
+// Weather falls rain or snow over the screen and tints it. Particles use their own random
+// numbers so they never change the rolls of the game.
+type Weather struct {
+	kind      string
+	size      rl.Vector2
+	particles []particle
+	flash     float32
+	rng       *rand.Rand
+}
+
+func NewWeather(screenSize rl.Vector2) Weather {
+	return Weather{
+		size: screenSize,
+		rng:  rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// particle count and fall speed in pixels per second of every weather
+var weatherFall = map[string]struct {
+	count int
+	speed rl.Vector2
+	size  float32
+}{
+	"rainy":  {count: 250, speed: rl.NewVector2(-120, 900), size: 14},
+	"stormy": {count: 500, speed: rl.NewVector2(-300, 1100), size: 18},
+	"snowy":  {count: 200, speed: rl.NewVector2(-30, 90), size: 3},
+}
+
+func (w *Weather) Update(dt float32, kind string) {
+	if kind != w.kind {
+		w.kind = kind
+		w.particles = []particle{}
+		fall, ok := weatherFall[kind]
+		for i := 0; ok && i < fall.count; i++ {
+			w.particles = append(w.particles, w.spawn(fall.speed, fall.size, w.rng.Float32()*w.size.Y))
+		}
+	}
+	fall := weatherFall[w.kind]
+	for i, p := range w.particles {
+		p.pos.X += p.speed.X * dt
+		p.pos.Y += p.speed.Y * dt
+		if p.pos.Y > w.size.Y {
+			p = w.spawn(fall.speed, fall.size, -p.size)
+		}
+		w.particles[i] = p
+	}
+	w.flash = max(w.flash-dt*2, 0)
+	// lightning, a few times a minute
+	if w.kind == "stormy" && w.rng.Float32() < dt/15 {
+		w.flash = 1
+	}
+}
+
+func (w *Weather) spawn(speed rl.Vector2, size float32, y float32) particle {
+	jitter := 0.8 + w.rng.Float32()*0.4
+	return particle{
+		// leave room for the wind to blow particles in from the right
+		pos:   rl.NewVector2(w.rng.Float32()*w.size.X*1.3, y),
+		speed: rl.NewVector2(speed.X*jitter, speed.Y*jitter),
+		size:  size * jitter,
+	}
+}
+
+// Tint is drawn over the day light, darker when it rains
+func (w *Weather) Tint() rl.Color {
+	switch w.kind {
+	case "rainy":
+		return rl.NewColor(60, 70, 95, 60)
+	case "stormy":
+		return rl.NewColor(35, 40, 70, 110)
+	case "snowy":
+		return rl.NewColor(210, 220, 240, 40)
+	}
+	return rl.NewColor(0, 0, 0, 0)
+}
+
+func (w *Weather) Draw() {
+	rl.DrawRectangle(0, 0, int32(w.size.X), int32(w.size.Y), w.Tint())
+	for _, p := range w.particles {
+		if w.kind == "snowy" {
+			rl.DrawCircleV(p.pos, p.size, rl.NewColor(255, 255, 255, 220))
+			continue
+		}
+		end := rl.Vector2Add(p.pos, rl.Vector2Scale(rl.Vector2Normalize(p.speed), p.size))
+		rl.DrawLineEx(p.pos, end, 2, rl.NewColor(170, 190, 230, 160))
+	}
+	if w.flash > 0 {
+		rl.DrawRectangle(0, 0, int32(w.size.X), int32(w.size.Y), rl.NewColor(255, 255, 255, uint8(w.flash*120)))
+	}
+}
@@ -0,0 +1,511 @@
+package sim
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sort"
+
+	"github.com/theanzy/farmsim/internal/crop"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/tileset"
+)
+
+type Cell struct {
+	X int
+	Y int
+}
+
+type FarmTile struct {
+	Cell Cell
+	// empty, digged, weeds, name of plant
+	State   string
+	IsWet   bool
+	CropAge int
+	// days in a row the crop was not watered
+	DryDays int
+	// a withered crop keeps its name in State until the shovel clears it
+	Withered bool
+	// raised by fertilizer, every harvest uses one point
+	Fertility int
+	// dry days since the crop was planted or last harvested
+	MissedDays int
+	// days the soil stayed digged without a crop
+	IdleDays int
+}
+
+type Tree struct {
+	// cell the tree is rooted on, as placed in the map
+	Cell Cell
+	// tile id of the tree in the tree_real layer
+	Kind      int
+	State     string // sapling, idle, dead (a stump), cleared
+	WoodCount int
+	// axe hits left until the tree falls or its stump comes out
+	Health int
+	// days since the tree was felled or planted
+	Age int
+}
+
+type Rock struct {
+	// top left cell of the rock, as placed in the map
+	Cell       Cell
+	State      string // idle, broken
+	StoneCount int
+}
+
+type Farm struct {
+	Tiles map[Cell]FarmTile
+	Trees []Tree
+	Rocks []Rock
+	Beds  map[Cell]bool
+	// cells under a roof, rain does not reach them
+	indoor map[Cell]bool
+	// land free of paths, buildings and obstacles, saplings are planted there
+	grass map[Cell]bool
+	// pond or sea
+	water map[Cell]string
+	// rolls crop quality and weeds, the front-end replaces it to replay a recording
+	Rand *rand.Rand
+	// days digged soil without a crop takes to turn back to empty, 0 keeps it forever
+	SoilDecayDays int
+	// chance of weeds growing on an empty tile overnight
+	WeedChance float64
+	crops      map[string]crop.Definition
+}
+
+const (
+	MaxFertility         = 3
+	DefaultSoilDecayDays = 3
+	DefaultWeedChance    = 0.05
+)
+
+type treeKind struct {
+	Wood   int
+	Health int
+}
+
+// tile ids of the tree_real layer, how much wood each tree gives and the hits it takes to fell
+var treeKinds = map[int]treeKind{
+	4102: {Wood: 5, Health: 4},
+	4103: {Wood: 2, Health: 3},
+}
+
+const (
+	// hits a stump takes to come out and the wood it gives
+	stumpHealth = 2
+	stumpWood   = 1
+	// days a stump takes to sprout and a sapling takes to grow into a tree
+	stumpSproutDays = 3
+	saplingGrowDays = 4
+)
+
+// trunk of a tree relative to the cell it is rooted on, the tree sprite hangs down from there
+var treeTrunk = Cell{X: 0, Y: 2}
+
+// layers drawn over the land that leave it free for a sapling
+var grassLayers = []string{"land", "land_details", "sea", "pond", "pond_details", "tree_real"}
+
+// tile id of the top left corner of a rock in the rocks layer and how much stone it gives
+var rockStone = map[int]int{
+	2993: 3,
+}
+
+func NewFarm(tmd *tileset.TileMapData, crops []crop.Definition) Farm {
+	f := Farm{
+		Tiles:         map[Cell]FarmTile{},
+		Trees:         []Tree{},
+		Rocks:         []Rock{},
+		Beds:          map[Cell]bool{},
+		indoor:        map[Cell]bool{},
+		grass:         map[Cell]bool{},
+		water:         map[Cell]string{},
+		Rand:          rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		SoilDecayDays: DefaultSoilDecayDays,
+		WeedChance:    DefaultWeedChance,
+		crops:         map[string]crop.Definition{},
+	}
+	for _, c := range crops {
+		f.crops[c.Name] = c
+	}
+	covered := map[Cell]bool{}
+	shore := map[Cell]bool{}
+	for _, layer := range tmd.Layers {
+		for i, id := range layer.Data {
+			if id == 0 {
+				continue
+			}
+			cell := Cell{X: i % tmd.Width, Y: i / tmd.Width}
+			if layer.Name == "land" {
+				f.grass[cell] = true
+			} else if !slices.Contains(grassLayers, layer.Name) {
+				covered[cell] = true
+			}
+			switch layer.Name {
+			case "pond", "sea":
+				f.water[cell] = layer.Name
+			case "land", "bridge":
+				shore[cell] = true
+			}
+			switch layer.Name {
+			case "farm_tile":
+				f.Tiles[cell] = FarmTile{Cell: cell, State: "empty"}
+			case "bed":
+				f.Beds[cell] = true
+			case "house_floor":
+				f.indoor[cell] = true
+			case "tree_real":
+				if _, ok := treeKinds[int(id)]; ok {
+					f.Trees = append(f.Trees, newTree(cell, int(id)))
+				}
+			case "rocks":
+				if stone, ok := rockStone[int(id)]; ok {
+					f.Rocks = append(f.Rocks, Rock{Cell: cell, State: "idle", StoneCount: stone})
+				}
+			}
+		}
+	}
+	for cell := range covered {
+		delete(f.grass, cell)
+	}
+	// the water edges under the land are shore
+	for cell := range shore {
+		delete(f.water, cell)
+	}
+	return f
+}
+
+// newTree returns a grown tree of kind rooted on cell
+func newTree(cell Cell, kind int) Tree {
+	k := treeKinds[kind]
+	return Tree{Cell: cell, Kind: kind, State: "idle", WoodCount: k.Wood, Health: k.Health}
+}
+
+// TreeRoot returns the cell a tree whose trunk stands on trunk is rooted on
+func TreeRoot(trunk Cell) Cell {
+	return Cell{X: trunk.X - treeTrunk.X, Y: trunk.Y - treeTrunk.Y}
+}
+
+// WaterAt names the water on cell, pond or sea, and is empty on land
+func (f *Farm) WaterAt(cell Cell) string {
+	return f.water[cell]
+}
+
+// Crop returns the definition of a crop, tile states name the crop growing on them
+func (f *Farm) Crop(name string) (crop.Definition, bool) {
+	c, ok := f.crops[name]
+	return c, ok
+}
+
+func (f *Farm) IsGrown(cell Cell) bool {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return false
+	}
+	c, ok := f.crops[ft.State]
+	return ok && !ft.Withered && c.IsGrown(ft.CropAge)
+}
+
+func (f *Farm) TreeAt(cell Cell) int {
+	for i, t := range f.Trees {
+		if t.Cell == cell {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *Farm) RockAt(cell Cell) int {
+	for i, r := range f.Rocks {
+		if r.Cell == cell {
+			return i
+		}
+	}
+	return -1
+}
+
+// CanDig is true on empty soil, weeds and withered crops
+func (f *Farm) CanDig(cell Cell) bool {
+	ft, ok := f.Tiles[cell]
+	return ok && (ft.State == "empty" || ft.State == "weeds" || ft.Withered)
+}
+
+// Dig tills empty soil, on a withered crop it clears the crop away and on weeds it pulls them out
+func (f *Farm) Dig(cell Cell) error {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return ErrNotFarmTile
+	}
+	if !f.CanDig(cell) {
+		return ErrNotEmpty
+	}
+	if ft.State == "weeds" {
+		ft.State = "empty"
+	} else {
+		ft.State = "digged"
+	}
+	ft.IdleDays = 0
+	ft.CropAge = 0
+	ft.DryDays = 0
+	ft.MissedDays = 0
+	ft.Withered = false
+	f.Tiles[cell] = ft
+	return nil
+}
+
+func (f *Farm) Water(cell Cell) error {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return ErrNotFarmTile
+	}
+	ft.IsWet = true
+	f.Tiles[cell] = ft
+	return nil
+}
+
+func (f *Farm) Plant(cell Cell, name string) error {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return ErrNotFarmTile
+	}
+	if _, ok := f.crops[name]; !ok {
+		return ErrUnknownCrop
+	}
+	if ft.State != "digged" {
+		return ErrNotDigged
+	}
+	ft.State = name
+	ft.CropAge = 0
+	ft.DryDays = 0
+	ft.MissedDays = 0
+	ft.IdleDays = 0
+	f.Tiles[cell] = ft
+	return nil
+}
+
+// Fertilize raises the fertility of tilled soil or of a growing crop, up to MaxFertility
+func (f *Farm) Fertilize(cell Cell, fertility int) error {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return ErrNotFarmTile
+	}
+	if ft.State == "empty" || ft.State == "weeds" || ft.Withered {
+		return ErrNotDigged
+	}
+	if ft.Fertility >= MaxFertility {
+		return ErrFertile
+	}
+	ft.Fertility = min(ft.Fertility+fertility, MaxFertility)
+	f.Tiles[cell] = ft
+	return nil
+}
+
+// Harvest resets a fully grown crop back to digged soil, or to its regrow age, and returns
+// the crop name and the quality it rolled
+func (f *Farm) Harvest(cell Cell) (string, items.Quality, error) {
+	ft, ok := f.Tiles[cell]
+	if !ok {
+		return "", items.Normal, ErrNotFarmTile
+	}
+	if !f.IsGrown(cell) {
+		return "", items.Normal, ErrNotGrown
+	}
+	name := ft.State
+	quality := f.rollQuality(ft)
+	ft.Fertility = max(ft.Fertility-1, 0)
+	ft.MissedDays = 0
+	if c := f.crops[name]; c.RegrowAge > 0 {
+		ft.CropAge = c.RegrowAge
+	} else {
+		ft.State = "digged"
+		ft.CropAge = 0
+	}
+	f.Tiles[cell] = ft
+	return name, quality, nil
+}
+
+// rollQuality picks the quality of a harvest, fertile soil raises the chances of silver and
+// gold while every missed watering lowers them
+func (f *Farm) rollQuality(ft FarmTile) items.Quality {
+	score := float64(ft.Fertility)*0.15 - float64(ft.MissedDays)*0.1
+	gold := max(0.02+score*0.5, 0)
+	silver := max(0.15+score, 0)
+	roll := f.Rand.Float64()
+	switch {
+	case roll < gold:
+		return items.Gold
+	case roll < gold+silver:
+		return items.Silver
+	}
+	return items.Normal
+}
+
+// Chop hits the tree rooted on cell and returns the wood it gave. A tree falls once its health runs
+// out and leaves a stump, chopping the stump out gives a little more wood.
+func (f *Farm) Chop(cell Cell) (int, error) {
+	idx := f.TreeAt(cell)
+	if idx == -1 {
+		return 0, ErrNoTree
+	}
+	t := &f.Trees[idx]
+	switch t.State {
+	case "sapling":
+		return 0, ErrTreeYoung
+	case "cleared":
+		return 0, ErrTreeChopped
+	}
+	t.Health -= 1
+	if t.Health > 0 {
+		return 0, nil
+	}
+	if t.State == "dead" {
+		t.State = "cleared"
+		return stumpWood, nil
+	}
+	t.State = "dead"
+	t.Health = stumpHealth
+	t.Age = 0
+	return t.WoodCount, nil
+}
+
+// CanPlantTree is true when the trunk of a tree rooted on cell stands on grass with no other
+// tree, sapling or stump next to it
+func (f *Farm) CanPlantTree(cell Cell) bool {
+	trunk := Cell{X: cell.X + treeTrunk.X, Y: cell.Y + treeTrunk.Y}
+	if !f.grass[trunk] {
+		return false
+	}
+	for _, t := range f.Trees {
+		if t.State == "cleared" {
+			continue
+		}
+		if abs(t.Cell.X-cell.X) <= 1 && abs(t.Cell.Y-cell.Y) <= 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// PlantTree puts a sapling of kind rooted on cell, it grows into a tree over saplingGrowDays
+func (f *Farm) PlantTree(cell Cell, kind int) error {
+	if _, ok := treeKinds[kind]; !ok {
+		return ErrUnknownTree
+	}
+	if !f.CanPlantTree(cell) {
+		return ErrNoRoom
+	}
+	sapling := Tree{Cell: cell, Kind: kind, State: "sapling"}
+	if idx := f.TreeAt(cell); idx != -1 {
+		f.Trees[idx] = sapling
+	} else {
+		f.Trees = append(f.Trees, sapling)
+	}
+	return nil
+}
+
+// growTrees sprouts old stumps into saplings and grows old saplings into trees
+func (f *Farm) growTrees() {
+	for i, t := range f.Trees {
+		if t.State != "dead" && t.State != "sapling" {
+			continue
+		}
+		t.Age += 1
+		switch {
+		case t.State == "dead" && t.Age >= stumpSproutDays:
+			t = Tree{Cell: t.Cell, Kind: t.Kind, State: "sapling"}
+		case t.State == "sapling" && t.Age >= saplingGrowDays:
+			t = newTree(t.Cell, t.Kind)
+		}
+		f.Trees[i] = t
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Mine breaks the rock whose top left corner is on cell and returns the stone it gave
+func (f *Farm) Mine(cell Cell) (int, error) {
+	idx := f.RockAt(cell)
+	if idx == -1 {
+		return 0, ErrNoRock
+	}
+	if f.Rocks[idx].State != "idle" {
+		return 0, ErrRockBroken
+	}
+	f.Rocks[idx].State = "broken"
+	return f.Rocks[idx].StoneCount, nil
+}
+
+// Rain waters every farm tile outside
+func (f *Farm) Rain() {
+	for cell, ft := range f.Tiles {
+		if !f.indoor[cell] {
+			ft.IsWet = true
+			f.Tiles[cell] = ft
+		}
+	}
+}
+
+// ChangeSeason withers the crops that do not grow in season
+func (f *Farm) ChangeSeason(season string) {
+	for cell, ft := range f.Tiles {
+		c, ok := f.crops[ft.State]
+		if !ok || c.GrowsIn(season) {
+			continue
+		}
+		ft.Withered = true
+		f.Tiles[cell] = ft
+	}
+}
+
+// AdvanceDay adds plant age if soil is wet and resets soil to dry. Crops left dry
+// for too many days in a row wither, digged soil left without a crop turns back to
+// empty and weeds may grow on empty tiles. Stumps sprout and saplings grow.
+func (f *Farm) AdvanceDay() {
+	f.growTrees()
+	for _, cell := range f.cells() {
+		ft := f.Tiles[cell]
+		if c, ok := f.crops[ft.State]; ok && !ft.Withered {
+			if ft.IsWet {
+				ft.CropAge = ft.CropAge + 1
+				ft.DryDays = 0
+			} else {
+				ft.DryDays += 1
+				ft.MissedDays += 1
+				ft.Withered = ft.DryDays >= c.WitherDays
+			}
+		}
+		switch ft.State {
+		case "digged":
+			ft.IdleDays += 1
+			if f.SoilDecayDays > 0 && ft.IdleDays >= f.SoilDecayDays {
+				ft.State = "empty"
+				ft.IdleDays = 0
+			}
+		case "empty":
+			if f.Rand.Float64() < f.WeedChance {
+				ft.State = "weeds"
+			}
+		}
+		ft.IsWet = false
+		f.Tiles[cell] = ft
+	}
+}
+
+// cells lists the farm tiles row by row, random rolls go through them in this order so a replay rolls the same
+func (f *Farm) cells() []Cell {
+	res := []Cell{}
+	for cell := range f.Tiles {
+		res = append(res, cell)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Y == res[j].Y {
+			return res[i].X < res[j].X
+		}
+		return res[i].Y < res[j].Y
+	})
+	return res
+}
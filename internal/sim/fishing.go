@@ -0,0 +1,163 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+var ErrInvalidFish = errors.New("invalid fish definition")
+
+// Fish is a species that bites in one kind of water during some hours of the day
+type Fish struct {
+	// item id of the catch
+	ID string `json:"id"`
+	// pond or sea
+	Location string `json:"location"`
+	// bites from From until To, in hours after midnight that go past 24 until the day ends
+	From float32 `json:"from"`
+	To   float32 `json:"to"`
+	// length range in cm, longer fish are of better quality
+	MinSize float32 `json:"minSize"`
+	MaxSize float32 `json:"maxSize"`
+	// how hard the fish pulls on the line, from 0 to 1
+	Difficulty float32 `json:"difficulty"`
+}
+
+// LoadFishes reads the fish species, every catch must be in catalog
+func LoadFishes(path string, catalog items.Catalog) ([]Fish, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fishes := []Fish{}
+	if err := json.Unmarshal(buffer, &fishes); err != nil {
+		return nil, err
+	}
+	for _, f := range fishes {
+		switch {
+		case f.Location != "pond" && f.Location != "sea":
+			return nil, fmt.Errorf("%w: %s has unknown location %q", ErrInvalidFish, f.ID, f.Location)
+		case f.From >= f.To:
+			return nil, fmt.Errorf("%w: %s bites no hour of the day", ErrInvalidFish, f.ID)
+		case f.MinSize <= 0 || f.MaxSize <= f.MinSize:
+			return nil, fmt.Errorf("%w: %s needs minSize below maxSize", ErrInvalidFish, f.ID)
+		}
+		if _, ok := catalog.Item(f.ID); !ok {
+			return nil, fmt.Errorf("%w: unknown item %q", ErrInvalidFish, f.ID)
+		}
+	}
+	return fishes, nil
+}
+
+// Bites is true when the fish bites in location at clock
+func (f Fish) Bites(location string, clock calendar.Clock) bool {
+	return f.Location == location && clock.Hours() >= f.From && clock.Hours() < f.To
+}
+
+// Bite picks one of the fish biting in location at the current time, false when none does
+func (g *Game) Bite(location string) (Fish, bool) {
+	biting := []Fish{}
+	for _, f := range g.Fishes {
+		if f.Bites(location, g.Clock) {
+			biting = append(biting, f)
+		}
+	}
+	if len(biting) == 0 {
+		return Fish{}, false
+	}
+	return biting[g.Farm.Rand.IntN(len(biting))], true
+}
+
+// RollSize picks the length of a caught fish
+func (f Fish) RollSize(rng *rand.Rand) float32 {
+	return f.MinSize + rng.Float32()*(f.MaxSize-f.MinSize)
+}
+
+// Quality grades a catch by its length, the longest fish of a species are gold
+func (f Fish) Quality(size float32) items.Quality {
+	t := (size - f.MinSize) / (f.MaxSize - f.MinSize)
+	switch {
+	case t >= 0.85:
+		return items.Gold
+	case t >= 0.6:
+		return items.Silver
+	}
+	return items.Normal
+}
+
+// Catch lands the fish id of quality from the water at cell, it has to bite there at this hour
+func (g *Game) Catch(cell Cell, id string, quality items.Quality) (Change, error) {
+	location := g.Farm.WaterAt(cell)
+	for _, f := range g.Fishes {
+		if f.ID == id && f.Bites(location, g.Clock) {
+			return Change{Item: id, Quality: quality, Delta: 1}, nil
+		}
+	}
+	return Change{}, ErrNoFish
+}
+
+const (
+	Reeling = "reeling"
+	Caught  = "caught"
+	Snapped = "snapped"
+	Escaped = "escaped"
+)
+
+// Reel is the reeling mini-game. The player holds the reel key to pull the line and has to keep
+// the tension inside the safe zone until the fish is landed, too much tension snaps the line and
+// the fish escapes when the progress runs out.
+type Reel struct {
+	Fish Fish
+	// line tension and landing progress, both from 0 to 1
+	Tension  float32
+	Progress float32
+	// tension the fish adds or takes every second and how long until it changes its mind
+	pull      float32
+	pullTimer float32
+	rng       *rand.Rand
+}
+
+func NewReel(fish Fish, rng *rand.Rand) Reel {
+	return Reel{Fish: fish, Tension: 0.5, Progress: 0.3, rng: rng}
+}
+
+// Zone returns the bounds of the safe tension, it narrows for harder fish
+func (r *Reel) Zone() (float32, float32) {
+	half := 0.2 - r.Fish.Difficulty*0.1
+	return 0.55 - half, 0.55 + half
+}
+
+// Update moves the mini-game forward by dt seconds and returns its state
+func (r *Reel) Update(dt float32, reeling bool) string {
+	r.pullTimer -= dt
+	if r.pullTimer <= 0 {
+		r.pullTimer = 0.4 + r.rng.Float32()*0.8
+		r.pull = (r.rng.Float32()*2 - 1) * (0.2 + r.Fish.Difficulty*0.8)
+	}
+	if reeling {
+		r.Tension += 0.6 * dt
+	} else {
+		r.Tension -= 0.5 * dt
+	}
+	r.Tension = min(max(r.Tension+r.pull*dt, 0), 1)
+	if low, high := r.Zone(); r.Tension >= low && r.Tension <= high {
+		r.Progress += 0.25 * dt
+	} else {
+		r.Progress -= 0.15 * dt
+	}
+	switch {
+	case r.Tension >= 1:
+		return Snapped
+	case r.Progress >= 1:
+		return Caught
+	case r.Progress <= 0:
+		return Escaped
+	}
+	return Reeling
+}
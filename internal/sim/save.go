@@ -0,0 +1,216 @@
+package sim
+
+import (
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/save"
+)
+
+func CellPosition(c Cell) save.Position {
+	return save.Position{X: float32(c.X), Y: float32(c.Y)}
+}
+
+func PositionCell(p save.Position) Cell {
+	return Cell{X: int(p.X), Y: int(p.Y)}
+}
+
+func (f *Farm) SaveTile(ft FarmTile) save.FarmTile {
+	return save.FarmTile{
+		Pos:        CellPosition(ft.Cell),
+		State:      ft.State,
+		IsWet:      ft.IsWet,
+		CropAge:    ft.CropAge,
+		DryDays:    ft.DryDays,
+		Withered:   ft.Withered,
+		Fertility:  ft.Fertility,
+		MissedDays: ft.MissedDays,
+		IdleDays:   ft.IdleDays,
+	}
+}
+
+func (f *Farm) SaveTree(t Tree) save.Tree {
+	return save.Tree{
+		Pos:       CellPosition(t.Cell),
+		State:     t.State,
+		WoodCount: t.WoodCount,
+		Kind:      t.Kind,
+		Health:    t.Health,
+		Age:       t.Age,
+	}
+}
+
+func (f *Farm) SaveRock(r Rock) save.Rock {
+	return save.Rock{
+		Pos:        CellPosition(r.Cell),
+		State:      r.State,
+		StoneCount: r.StoneCount,
+	}
+}
+
+func (f *Farm) SaveRocks() []save.Rock {
+	rocks := []save.Rock{}
+	for _, r := range f.Rocks {
+		rocks = append(rocks, f.SaveRock(r))
+	}
+	return rocks
+}
+
+// RestoreRocks overwrites the rocks present in the save, others keep their state
+func (f *Farm) RestoreRocks(rocks []save.Rock) {
+	for _, sr := range rocks {
+		if idx := f.RockAt(PositionCell(sr.Pos)); idx != -1 {
+			f.Rocks[idx].State = sr.State
+			f.Rocks[idx].StoneCount = sr.StoneCount
+		}
+	}
+}
+
+func (f *Farm) Save() ([]save.FarmTile, []save.Tree) {
+	// keep the output stable so save files diff nicely
+	farmTiles := []save.FarmTile{}
+	for _, cell := range f.cells() {
+		farmTiles = append(farmTiles, f.SaveTile(f.Tiles[cell]))
+	}
+	trees := []save.Tree{}
+	for _, t := range f.Trees {
+		trees = append(trees, f.SaveTree(t))
+	}
+	return farmTiles, trees
+}
+
+// Restore overwrites the tiles and trees present in the save, others keep their state. Trees
+// planted since the map was made are added.
+func (f *Farm) Restore(farmTiles []save.FarmTile, trees []save.Tree) {
+	for _, sft := range farmTiles {
+		cell := PositionCell(sft.Pos)
+		if ft, ok := f.Tiles[cell]; ok {
+			ft.State = sft.State
+			ft.IsWet = sft.IsWet
+			ft.CropAge = sft.CropAge
+			ft.DryDays = sft.DryDays
+			ft.Withered = sft.Withered
+			ft.Fertility = sft.Fertility
+			ft.MissedDays = sft.MissedDays
+			ft.IdleDays = sft.IdleDays
+			f.Tiles[cell] = ft
+		}
+	}
+	for _, st := range trees {
+		cell := PositionCell(st.Pos)
+		idx := f.TreeAt(cell)
+		if idx == -1 {
+			if _, ok := treeKinds[st.Kind]; !ok {
+				continue
+			}
+			f.Trees = append(f.Trees, Tree{Cell: cell, Kind: st.Kind})
+			idx = len(f.Trees) - 1
+		}
+		t := &f.Trees[idx]
+		// saves written while a tree was still shaking
+		if st.State == "shaking" {
+			st.State = "dead"
+		}
+		t.State = st.State
+		t.WoodCount = st.WoodCount
+		t.Age = st.Age
+		t.Health = st.Health
+		if t.Health <= 0 && t.State == "dead" {
+			t.Health = stumpHealth
+		} else if t.Health <= 0 {
+			t.Health = treeKinds[t.Kind].Health
+		}
+	}
+}
+
+// Save returns the farm, inventory and shop state. Player position, farm name and playtime are
+// owned by the front-end and left empty.
+func (g *Game) Save() save.Data {
+	farmTiles, trees := g.Farm.Save()
+	data := save.Data{
+		Day:        g.Day,
+		Clock:      float64(g.Clock),
+		EnergyUsed: MaxEnergy - g.Energy,
+		WaterUsed:  MaxWaterCharges - g.WaterCharges,
+		Weather:    g.Weather,
+		Forecast:   g.Forecast,
+		FarmTiles:  farmTiles,
+		Trees:      trees,
+		Rocks:      g.Farm.SaveRocks(),
+		Inventory: save.Inventory{
+			Items:   []save.ItemStack{},
+			Deposit: g.Inventory.Deposit(),
+		},
+		Shops: []save.Shop{},
+	}
+	for _, item := range g.Inventory.Items() {
+		data.Inventory.Items = append(data.Inventory.Items, save.ItemStack{ID: item.ID, Quality: int(item.Quality), Quantity: item.Quantity})
+	}
+	data.Hotbar = save.Hotbar{Slots: []save.HotbarSlot{}, Selected: g.Hotbar.Selected}
+	for _, s := range g.Hotbar.Slots {
+		data.Hotbar.Slots = append(data.Hotbar.Slots, save.HotbarSlot{ID: s.ID, Quality: int(s.Quality)})
+	}
+	s := save.Shop{Name: g.Shop.Name(), Items: []save.ItemStack{}}
+	for _, item := range g.Shop.Items {
+		s.Items = append(s.Items, save.ItemStack{ID: item.ID, Quantity: item.Quantity})
+	}
+	data.Shops = append(data.Shops, s)
+	return data
+}
+
+func (g *Game) Load(data save.Data) {
+	g.Day = data.Day
+	// saves written before the clock start in the morning
+	g.Clock = max(calendar.Clock(data.Clock), calendar.DayStart)
+	g.Energy = min(max(MaxEnergy-data.EnergyUsed, 0), MaxEnergy)
+	g.WaterCharges = min(max(MaxWaterCharges-data.WaterUsed, 0), MaxWaterCharges)
+	g.SetWeather(data.Weather, data.Forecast)
+	g.Shop = g.seedShop()
+	g.Farm.Restore(data.FarmTiles, data.Trees)
+	g.Farm.RestoreRocks(data.Rocks)
+
+	g.Inventory.Restore(g.stacks(data.Inventory.Items), data.Inventory.Deposit)
+	// saves written before the basic tools were items have none of them
+	for _, item := range g.Catalog.Items() {
+		if item.Type == "tool" && item.Tier == 0 && g.Inventory.Count(item.ID) == 0 {
+			g.Inventory.Increase(item.ID, 1)
+		}
+	}
+	g.Blacksmith = g.blacksmithShop()
+	g.Hotbar = items.Hotbar{}
+	for i, s := range data.Hotbar.Slots[:min(len(data.Hotbar.Slots), items.HotbarSize)] {
+		g.Hotbar.Slots[i] = items.Stack{ID: s.ID, Quality: items.Quality(s.Quality)}
+	}
+	g.Hotbar.Select(data.Hotbar.Selected)
+	g.Hotbar.Sync(&g.Inventory)
+
+	for _, s := range data.Shops {
+		if s.Name != g.Shop.Name() {
+			continue
+		}
+		g.Shop.Restore(g.quantities(s.Items))
+	}
+}
+
+// quantities keys stacks by item id, stacks of version 1 saves only have a display name
+func (g *Game) quantities(stacks []save.ItemStack) map[string]int {
+	res := map[string]int{}
+	for stack, quantity := range g.stacks(stacks) {
+		res[stack.ID] += quantity
+	}
+	return res
+}
+
+// stacks keys stacks by item id and quality
+func (g *Game) stacks(stacks []save.ItemStack) map[items.Stack]int {
+	res := map[items.Stack]int{}
+	for _, stack := range stacks {
+		id := stack.ID
+		if id == "" {
+			if item, ok := g.Catalog.ByName(stack.Name); ok {
+				id = item.ID
+			}
+		}
+		res[items.Stack{ID: id, Quality: items.Quality(stack.Quality)}] += stack.Quantity
+	}
+	return res
+}
@@ -0,0 +1,326 @@
+package sim
+
+import (
+	"errors"
+
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/crop"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/tileset"
+)
+
+var (
+	ErrNotFarmTile  = errors.New("not a farm tile")
+	ErrNotEmpty     = errors.New("tile is not empty")
+	ErrNotDigged    = errors.New("tile is not digged")
+	ErrNotGrown     = errors.New("crop is not fully grown")
+	ErrUnknownCrop  = errors.New("unknown crop")
+	ErrNoSeed       = errors.New("no seed left")
+	ErrNoTree       = errors.New("no tree")
+	ErrTreeChopped  = errors.New("tree is already chopped")
+	ErrTreeYoung    = errors.New("tree is too young to chop")
+	ErrUnknownTree  = errors.New("unknown tree")
+	ErrNoRoom       = errors.New("no room for a tree")
+	ErrNoSapling    = errors.New("no sapling left")
+	ErrFertile      = errors.New("soil is as fertile as it gets")
+	ErrNoFertilizer = errors.New("not a fertilizer")
+	ErrExhausted    = errors.New("too tired to use tools")
+	ErrCanEmpty     = errors.New("watering can is empty")
+	ErrNoRock       = errors.New("no rock")
+	ErrRockBroken   = errors.New("rock is already broken")
+	ErrBasicTool    = errors.New("basic tools cannot be sold")
+	ErrNoPond       = errors.New("no pond to fill the can from")
+	ErrNoFish       = errors.New("the fish does not bite here at this hour")
+)
+
+const (
+	MaxEnergy float32 = 100
+	// tiles a full watering can waters
+	MaxWaterCharges = 20
+)
+
+// energy used by one swing of every tool, a charged swing takes it once more for every level
+var toolEnergy = map[string]float32{
+	"shovel":  2,
+	"water":   2,
+	"axe":     4,
+	"pickaxe": 4,
+	"rod":     2,
+}
+
+// Game holds the rules and state of one farm without any rendering. The raylib front-end
+// and the dedicated server both forward player commands to it.
+type Game struct {
+	Day   int
+	Clock calendar.Clock
+	// energy of the local player, tools use it up and sleeping restores it
+	Energy float32
+	// water left in the watering can, refilled at the pond
+	WaterCharges int
+	// weather of today and the forecast for tomorrow
+	Weather  string
+	Forecast string
+	Farm     Farm
+	Catalog  items.Catalog
+	// species that bite in the pond and the sea
+	Fishes    []Fish
+	Inventory items.Inventory
+	Shop      items.Shop
+	// sells tool upgrades, its stock follows the tools in the inventory
+	Blacksmith items.Shop
+	// inventory stacks at hand, saved with the game
+	Hotbar items.Hotbar
+}
+
+func NewGame(tmd *tileset.TileMapData, crops []crop.Definition, fishes []Fish, catalog items.Catalog) *Game {
+	g := &Game{
+		Day:          0,
+		Clock:        calendar.DayStart,
+		Energy:       MaxEnergy,
+		WaterCharges: MaxWaterCharges,
+		Weather:      Sunny,
+		Farm:         NewFarm(tmd, crops),
+		Catalog:      catalog,
+		Fishes:       fishes,
+		Inventory:    items.NewInventory(catalog.Items()),
+	}
+	g.Shop = g.seedShop()
+	g.Blacksmith = g.blacksmithShop()
+	g.Hotbar.Sync(&g.Inventory)
+	g.Forecast = rollWeather(calendar.DateOf(g.Day+1).Season, g.Farm.Rand)
+	return g
+}
+
+func (g *Game) Date() calendar.Date {
+	return calendar.DateOf(g.Day)
+}
+
+// SetDay jumps to the morning of day as told by a LAN host, the seed shop is restocked when the season differs
+func (g *Game) SetDay(day int) {
+	season := g.Date().Season
+	if day != g.Day {
+		g.Energy = MaxEnergy
+	}
+	g.Day = day
+	g.Clock = calendar.DayStart
+	if g.Date().Season != season {
+		g.Shop = g.seedShop()
+	}
+}
+
+// SetWeather overwrites the weather and forecast, unknown ones are sunny
+func (g *Game) SetWeather(weather string, forecast string) {
+	g.Weather = knownWeather(weather)
+	g.Forecast = knownWeather(forecast)
+}
+
+// Tick moves the clock forward by dt seconds and returns true once the day is over
+func (g *Game) Tick(dt float32) bool {
+	g.Clock = min(g.Clock+calendar.Clock(dt*calendar.MinutesPerSecond), calendar.DayEnd)
+	return g.Clock >= calendar.DayEnd
+}
+
+// seedShop stocks fertilizers and the seeds of the crops growing in the current season
+func (g *Game) seedShop() items.Shop {
+	season := g.Date().Season
+	seeds := []items.Item{}
+	for _, item := range g.Catalog.Items() {
+		if c, ok := g.Farm.Crop(item.Crop); (ok && c.GrowsIn(season)) || item.Type == "fertilizer" || item.Type == "sapling" {
+			seeds = append(seeds, item)
+		}
+	}
+	return items.NewSeedShop("Seed merchant", seeds)
+}
+
+// Change is what a command gave to or took from the player doing it, the caller applies it to
+// the inventory of that player
+type Change struct {
+	Item    string
+	Quality items.Quality
+	Delta   int
+}
+
+// Apply adds the change to inventory, a loss never takes a stack below zero
+func (c Change) Apply(inventory *items.Inventory) {
+	if c.Delta > 0 {
+		inventory.Add(c.Item, c.Quality, c.Delta)
+	} else if c.Delta < 0 {
+		inventory.Remove(c.Item, c.Quality, -c.Delta)
+	}
+}
+
+// owns is true when inventory holds an item of normal quality. The host has no inventory of
+// LAN clients and passes nil, it trusts them to own what they use.
+func owns(inventory *items.Inventory, id string) bool {
+	return inventory == nil || inventory.Count(id) > 0
+}
+
+func (g *Game) Dig(cell Cell) error {
+	return g.Farm.Dig(cell)
+}
+
+func (g *Game) Water(cell Cell) error {
+	return g.Farm.Water(cell)
+}
+
+// Plant uses one seed of the crop from inventory
+func (g *Game) Plant(inventory *items.Inventory, cell Cell, name string) (Change, error) {
+	c, ok := g.Farm.Crop(name)
+	if !ok {
+		return Change{}, ErrUnknownCrop
+	}
+	if !owns(inventory, c.Seed) {
+		return Change{}, ErrNoSeed
+	}
+	if err := g.Farm.Plant(cell, name); err != nil {
+		return Change{}, err
+	}
+	return Change{Item: c.Seed, Delta: -1}, nil
+}
+
+// Fertilize uses one fertilizer from inventory
+func (g *Game) Fertilize(inventory *items.Inventory, cell Cell, id string) (Change, error) {
+	item, ok := g.Catalog.Item(id)
+	if !ok || item.Type != "fertilizer" || !owns(inventory, id) {
+		return Change{}, ErrNoFertilizer
+	}
+	if err := g.Farm.Fertilize(cell, item.Fertility); err != nil {
+		return Change{}, err
+	}
+	return Change{Item: id, Delta: -1}, nil
+}
+
+// Harvest picks the grown crop on cell and gives it at the quality rolled for it
+func (g *Game) Harvest(cell Cell) (Change, error) {
+	name, quality, err := g.Farm.Harvest(cell)
+	if err != nil {
+		return Change{}, err
+	}
+	c, _ := g.Farm.Crop(name)
+	return Change{Item: c.Crop, Quality: quality, Delta: 1}, nil
+}
+
+// Chop hits the tree rooted on cell, it gives wood once the tree falls and once its stump is cleared
+func (g *Game) Chop(cell Cell) (Change, error) {
+	wood, err := g.Farm.Chop(cell)
+	if err != nil || wood == 0 {
+		return Change{}, err
+	}
+	return Change{Item: "wood", Delta: wood}, nil
+}
+
+// PlantTree uses one sapling from inventory to plant a tree rooted on cell
+func (g *Game) PlantTree(inventory *items.Inventory, cell Cell, id string) (Change, error) {
+	item, ok := g.Catalog.Item(id)
+	if !ok || item.Type != "sapling" {
+		return Change{}, ErrUnknownTree
+	}
+	if !owns(inventory, id) {
+		return Change{}, ErrNoSapling
+	}
+	if err := g.Farm.PlantTree(cell, item.Tree); err != nil {
+		return Change{}, err
+	}
+	return Change{Item: id, Delta: -1}, nil
+}
+
+// Mine breaks the rock on cell and gives its stone
+func (g *Game) Mine(cell Cell) (Change, error) {
+	stone, err := g.Farm.Mine(cell)
+	if err != nil {
+		return Change{}, err
+	}
+	return Change{Item: "stone", Delta: stone}, nil
+}
+
+func (g *Game) IsBed(cell Cell) bool {
+	return g.Farm.Beds[cell]
+}
+
+// Sleep starts the next day in the morning, out of season crops die when a new season starts.
+// The forecast becomes the weather and rain waters the farm.
+func (g *Game) Sleep() {
+	season := g.Date().Season
+	g.Day += 1
+	g.Clock = calendar.DayStart
+	g.Energy = MaxEnergy
+	g.Farm.AdvanceDay()
+	if g.Date().Season != season {
+		g.Farm.ChangeSeason(g.Date().Season)
+		g.Shop = g.seedShop()
+	}
+	g.Weather = g.Forecast
+	g.Forecast = rollWeather(calendar.DateOf(g.Day+1).Season, g.Farm.Rand)
+	if IsRaining(g.Weather) {
+		g.Farm.Rain()
+	}
+}
+
+// PassOut ends the day of a player who stayed up until DayEnd, it costs them part of their money
+// and they wake up with half their energy
+func (g *Game) PassOut() {
+	g.Sleep()
+	g.Penalize()
+}
+
+// Penalize takes the money lost by passing out, 10% of the deposit up to 1000, and half the energy
+func (g *Game) Penalize() {
+	g.Inventory.Withdraw(min(g.Inventory.Deposit()*0.1, 1000))
+	g.Energy = min(g.Energy, MaxEnergy/2)
+}
+
+// UseTool spends the energy a swing of tool charged to level takes, an exhausted player cannot
+// use tools and an empty watering can does not swing
+func (g *Game) UseTool(tool string, level int) error {
+	if g.Energy <= 0 {
+		return ErrExhausted
+	}
+	if tool == "water" && g.WaterCharges <= 0 {
+		return ErrCanEmpty
+	}
+	g.Energy = max(g.Energy-toolEnergy[tool]*float32(level+1), 0)
+	return nil
+}
+
+// Pour takes one charge out of the watering can for every farm tile in cells and returns the
+// tiles the water was enough for
+func (g *Game) Pour(cells []Cell) []Cell {
+	res := []Cell{}
+	for _, c := range cells {
+		if _, ok := g.Farm.Tiles[c]; ok && len(res) < g.WaterCharges {
+			res = append(res, c)
+		}
+	}
+	g.WaterCharges -= len(res)
+	return res
+}
+
+// RefillCan fills the watering can up to MaxWaterCharges from the pond on cell, sea water does not do
+func (g *Game) RefillCan(cell Cell) error {
+	if g.Farm.WaterAt(cell) != "pond" {
+		return ErrNoPond
+	}
+	g.WaterCharges = MaxWaterCharges
+	return nil
+}
+
+// IsTired is true when energy runs low, tired players walk slower
+func (g *Game) IsTired() bool {
+	return g.Energy < MaxEnergy*0.2
+}
+
+func (g *Game) Buy(id string, quantity int) error {
+	return g.Shop.Buy(&g.Inventory, id, quantity)
+}
+
+func (g *Game) Sell(id string, quality items.Quality, quantity int) error {
+	if item, ok := g.Catalog.Item(id); ok && item.Type == "tool" && item.Tier == 0 {
+		return ErrBasicTool
+	}
+	if err := g.Shop.Sell(&g.Inventory, id, quality, quantity); err != nil {
+		return err
+	}
+	// a sold tool upgrade is back in stock at the blacksmith
+	g.Blacksmith = g.blacksmithShop()
+	return nil
+}
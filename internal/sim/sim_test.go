@@ -0,0 +1,329 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+// newTestGame starts a game with sunny weather and no random weeds
+func newTestGame(t *testing.T) *Game {
+	g := NewTestGame(t, 1)
+	g.Farm.WeedChance = 0
+	g.Weather = Sunny
+	g.Forecast = Sunny
+	return g
+}
+
+// testCells returns a farm tile, a free spot for a tree and the corner of a rock
+func testCells(t *testing.T, g *Game) (Cell, Cell, Cell) {
+	t.Helper()
+	farm := g.Farm.cells()[0]
+	grass := Cell{}
+	found := false
+	for y := 0; y < 100 && !found; y++ {
+		for x := 0; x < 100 && !found; x++ {
+			grass = Cell{X: x, Y: y}
+			found = g.Farm.CanPlantTree(grass)
+		}
+	}
+	if !found || len(g.Farm.Rocks) == 0 {
+		t.Fatal("the map has no free grass or no rock")
+	}
+	return farm, grass, g.Farm.Rocks[0].Cell
+}
+
+func TestCommandErrors(t *testing.T) {
+	g := newTestGame(t)
+	farm, grass, rock := testCells(t, g)
+	pond := Cell{}
+	for cell, water := range g.Farm.water {
+		if water == "pond" {
+			pond = cell
+			break
+		}
+	}
+	nowhere := Cell{X: -1, Y: -1}
+	tests := []struct {
+		name string
+		run  func(g *Game) error
+		want error
+	}{
+		{"dig outside the farm", func(g *Game) error {
+			return g.Dig(nowhere)
+		}, ErrNotFarmTile},
+		{"dig twice", func(g *Game) error {
+			g.Dig(farm)
+			return g.Dig(farm)
+		}, ErrNotEmpty},
+		{"water outside the farm", func(g *Game) error {
+			return g.Water(nowhere)
+		}, ErrNotFarmTile},
+		{"plant an unknown crop", func(g *Game) error {
+			g.Dig(farm)
+			_, err := g.Plant(&g.Inventory, farm, "cactus")
+			return err
+		}, ErrUnknownCrop},
+		{"plant without seeds", func(g *Game) error {
+			g.Dig(farm)
+			_, err := g.Plant(&g.Inventory, farm, "carrot")
+			return err
+		}, ErrNoSeed},
+		{"plant on untilled soil", func(g *Game) error {
+			_, err := g.Plant(&g.Inventory, farm, "wheat")
+			return err
+		}, ErrNotDigged},
+		{"plant for a trusted client", func(g *Game) error {
+			g.Dig(farm)
+			_, err := g.Plant(nil, farm, "carrot")
+			return err
+		}, nil},
+		{"fertilize with a seed", func(g *Game) error {
+			g.Dig(farm)
+			_, err := g.Fertilize(&g.Inventory, farm, "wheat_seed")
+			return err
+		}, ErrNoFertilizer},
+		{"fertilize without fertilizer", func(g *Game) error {
+			g.Dig(farm)
+			_, err := g.Fertilize(&g.Inventory, farm, "basic_fertilizer")
+			return err
+		}, ErrNoFertilizer},
+		{"fertilize untilled soil", func(g *Game) error {
+			g.Inventory.Increase("basic_fertilizer", 1)
+			_, err := g.Fertilize(&g.Inventory, farm, "basic_fertilizer")
+			return err
+		}, ErrNotDigged},
+		{"harvest a seedling", func(g *Game) error {
+			g.Dig(farm)
+			g.Plant(&g.Inventory, farm, "wheat")
+			_, err := g.Harvest(farm)
+			return err
+		}, ErrNotGrown},
+		{"chop where no tree stands", func(g *Game) error {
+			_, err := g.Chop(nowhere)
+			return err
+		}, ErrNoTree},
+		{"chop a sapling", func(g *Game) error {
+			g.PlantTree(nil, grass, "oak_sapling")
+			_, err := g.Chop(grass)
+			return err
+		}, ErrTreeYoung},
+		{"plant a seed as a tree", func(g *Game) error {
+			_, err := g.PlantTree(&g.Inventory, grass, "wheat_seed")
+			return err
+		}, ErrUnknownTree},
+		{"plant a tree without saplings", func(g *Game) error {
+			_, err := g.PlantTree(&g.Inventory, grass, "oak_sapling")
+			return err
+		}, ErrNoSapling},
+		{"plant a tree on another", func(g *Game) error {
+			_, err := g.PlantTree(nil, g.Farm.Trees[0].Cell, "oak_sapling")
+			return err
+		}, ErrNoRoom},
+		{"mine where no rock lies", func(g *Game) error {
+			_, err := g.Mine(nowhere)
+			return err
+		}, ErrNoRock},
+		{"mine a broken rock", func(g *Game) error {
+			g.Mine(rock)
+			_, err := g.Mine(rock)
+			return err
+		}, ErrRockBroken},
+		{"catch a fish on land", func(g *Game) error {
+			_, err := g.Catch(farm, "carp", items.Normal)
+			return err
+		}, ErrNoFish},
+		{"catch a sea fish in the pond", func(g *Game) error {
+			_, err := g.Catch(pond, "sardine", items.Normal)
+			return err
+		}, ErrNoFish},
+		{"catch a night fish at noon", func(g *Game) error {
+			g.Clock = 12 * 60
+			_, err := g.Catch(pond, "catfish", items.Normal)
+			return err
+		}, ErrNoFish},
+		{"catch a night fish at night", func(g *Game) error {
+			g.Clock = 20 * 60
+			_, err := g.Catch(pond, "catfish", items.Normal)
+			return err
+		}, nil},
+		{"fill the can on land", func(g *Game) error {
+			return g.RefillCan(farm)
+		}, ErrNoPond},
+		{"swing while exhausted", func(g *Game) error {
+			g.Energy = 0
+			return g.UseTool("axe", 0)
+		}, ErrExhausted},
+		{"water with an empty can", func(g *Game) error {
+			g.WaterCharges = 0
+			return g.UseTool("water", 0)
+		}, ErrCanEmpty},
+		{"sell a basic tool", func(g *Game) error {
+			return g.Sell("axe", items.Normal, 1)
+		}, ErrBasicTool},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(newTestGame(t)); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandChanges(t *testing.T) {
+	g := newTestGame(t)
+	farm, _, rock := testCells(t, g)
+	g.Dig(farm)
+	change, err := g.Plant(&g.Inventory, farm, "wheat")
+	if err != nil || change != (Change{Item: "wheat_seed", Delta: -1}) {
+		t.Fatalf("plant gave %+v, %v", change, err)
+	}
+	change.Apply(&g.Inventory)
+	if n := g.Inventory.Count("wheat_seed"); n != 4 {
+		t.Errorf("%d seeds left, want 4", n)
+	}
+	for !g.Farm.IsGrown(farm) {
+		g.Water(farm)
+		g.Farm.AdvanceDay()
+	}
+	change, err = g.Harvest(farm)
+	if err != nil || change.Item != "wheat" || change.Delta != 1 {
+		t.Errorf("harvest gave %+v, %v", change, err)
+	}
+	change, err = g.Mine(rock)
+	if err != nil || change != (Change{Item: "stone", Delta: g.Farm.Rocks[0].StoneCount}) {
+		t.Errorf("mine gave %+v, %v", change, err)
+	}
+}
+
+func TestAdvanceDay(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game, c Cell)
+		// called before every night
+		each   func(g *Game, c Cell)
+		days   int
+		want   string
+		age    int
+		wither bool
+	}{
+		{
+			name:  "watered crop grows",
+			setup: func(g *Game, c Cell) { g.Dig(c); g.Plant(nil, c, "wheat") },
+			each:  func(g *Game, c Cell) { g.Water(c) },
+			days:  2,
+			want:  "wheat",
+			age:   2,
+		},
+		{
+			name:  "dry crop stays",
+			setup: func(g *Game, c Cell) { g.Dig(c); g.Plant(nil, c, "wheat") },
+			days:  1,
+			want:  "wheat",
+		},
+		{
+			name:   "crop withers after its dry days",
+			setup:  func(g *Game, c Cell) { g.Dig(c); g.Plant(nil, c, "wheat") },
+			days:   3,
+			want:   "wheat",
+			wither: true,
+		},
+		{
+			name:  "tilled soil turns back to empty",
+			setup: func(g *Game, c Cell) { g.Dig(c) },
+			days:  DefaultSoilDecayDays,
+			want:  "empty",
+		},
+		{
+			name:  "soil decay turned off",
+			setup: func(g *Game, c Cell) { g.Dig(c); g.Farm.SoilDecayDays = 0 },
+			days:  10,
+			want:  "digged",
+		},
+		{
+			name:  "weeds grow on empty soil",
+			setup: func(g *Game, c Cell) { g.Farm.WeedChance = 1 },
+			days:  1,
+			want:  "weeds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t)
+			c := g.Farm.cells()[0]
+			tt.setup(g, c)
+			for range tt.days {
+				if tt.each != nil {
+					tt.each(g, c)
+				}
+				g.Farm.AdvanceDay()
+			}
+			ft := g.Farm.Tiles[c]
+			if ft.State != tt.want || ft.CropAge != tt.age || ft.Withered != tt.wither || ft.IsWet {
+				t.Errorf("got %+v, want %s aged %d withered %v and dry", ft, tt.want, tt.age, tt.wither)
+			}
+		})
+	}
+}
+
+func TestSleep(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(g *Game, c Cell)
+		sleep func(g *Game)
+		check func(g *Game, c Cell) bool
+	}{
+		{
+			name:  "wakes up rested in the morning",
+			setup: func(g *Game, c Cell) { g.Energy = 10; g.Clock = 20 * 60 },
+			sleep: (*Game).Sleep,
+			check: func(g *Game, c Cell) bool {
+				return g.Day == 1 && g.Clock == calendar.DayStart && g.Energy == MaxEnergy
+			},
+		},
+		{
+			name:  "rain waters the farm",
+			setup: func(g *Game, c Cell) { g.Forecast = Rainy },
+			sleep: (*Game).Sleep,
+			check: func(g *Game, c Cell) bool {
+				return g.Weather == Rainy && g.Farm.Tiles[c].IsWet
+			},
+		},
+		{
+			name: "a new season withers the crops out of season",
+			setup: func(g *Game, c Cell) {
+				g.Day = calendar.DaysPerSeason - 1
+				g.Dig(c)
+				g.Plant(nil, c, "cauliflower")
+				g.Water(c)
+			},
+			sleep: (*Game).Sleep,
+			check: func(g *Game, c Cell) bool {
+				return g.Date().Season == "summer" && g.Farm.Tiles[c].Withered
+			},
+		},
+		{
+			name:  "passing out costs money and energy",
+			setup: func(g *Game, c Cell) { g.Inventory.Restore(nil, 500) },
+			sleep: (*Game).PassOut,
+			check: func(g *Game, c Cell) bool {
+				return g.Inventory.Deposit() == 450 && g.Energy == MaxEnergy/2
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t)
+			c := g.Farm.cells()[0]
+			tt.setup(g, c)
+			tt.sleep(g)
+			if !tt.check(g, c) {
+				t.Errorf("day %d at %s, weather %s, energy %.0f, deposit %.0f, tile %+v",
+					g.Day, g.Clock, g.Weather, g.Energy, g.Inventory.Deposit(), g.Farm.Tiles[c])
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"math/rand/v2"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/theanzy/farmsim/internal/crop"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/tileset"
+)
+
+// NewTestGame starts a game on the map and data of the repository for the tests of any package,
+// its random numbers are seeded with seed
+func NewTestGame(t testing.TB, seed uint64) *Game {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	resources := filepath.Join(filepath.Dir(file), "..", "..", "resources")
+	catalog, err := items.LoadCatalog(filepath.Join(resources, "data", "items.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crops, err := crop.Load(filepath.Join(resources, "data", "crops.json"), catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fishes, err := LoadFishes(filepath.Join(resources, "data", "fish.json"), catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmd, err := tileset.ParseMap(filepath.Join(resources, "map", "0.tmj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame(&tmd, crops, fishes, catalog)
+	g.Farm.Rand = rand.New(rand.NewPCG(seed, seed))
+	return g
+}
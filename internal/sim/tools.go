@@ -0,0 +1,60 @@
+package sim
+
+import "github.com/theanzy/farmsim/internal/items"
+
+// ToolTiers names the tiers of upgradable tools, basic tools are items of tier 0
+var ToolTiers = []string{"basic", "copper", "iron", "gold"}
+
+// area hit by a tool charged to each level, length reaches away from the player and width
+// spreads around the cell in front of them
+var chargeAreas = []struct{ length, width int }{
+	{1, 1},
+	{3, 1},
+	{3, 3},
+	{5, 3},
+}
+
+// ToolArea returns the cells a swing charged to level hits, cell is the one in front of the player
+// and dx is 1 when they face right or -1 when they face left
+func ToolArea(cell Cell, dx int, level int) []Cell {
+	area := chargeAreas[min(max(level, 0), len(chargeAreas)-1)]
+	res := []Cell{}
+	for y := -area.width / 2; y <= area.width/2; y++ {
+		for x := 0; x < area.length; x++ {
+			res = append(res, Cell{X: cell.X + x*dx, Y: cell.Y + y})
+		}
+	}
+	return res
+}
+
+// ToolTier returns the best tier of tool the player owns, upgrades bought from the blacksmith
+// are kept in the inventory
+func (g *Game) ToolTier(tool string) int {
+	tier := 0
+	for _, item := range g.Inventory.Items() {
+		if item.Type == "tool" && item.Tool == tool {
+			tier = max(tier, item.Tier)
+		}
+	}
+	return tier
+}
+
+// blacksmithShop stocks the next upgrade of every tool
+func (g *Game) blacksmithShop() items.Shop {
+	upgrades := []items.Item{}
+	for _, item := range g.Catalog.Items() {
+		if item.Type == "tool" && item.Tier == g.ToolTier(item.Tool)+1 {
+			upgrades = append(upgrades, item)
+		}
+	}
+	return items.NewToolShop("Blacksmith", upgrades)
+}
+
+// Upgrade buys a tool upgrade from the blacksmith, who then stocks the next tier
+func (g *Game) Upgrade(id string) error {
+	if err := g.Blacksmith.Buy(&g.Inventory, id, 1); err != nil {
+		return err
+	}
+	g.Blacksmith = g.blacksmithShop()
+	return nil
+}
@@ -0,0 +1,49 @@
+package sim
+
+import (
+	"math/rand/v2"
+)
+
+const (
+	Sunny  = "sunny"
+	Rainy  = "rainy"
+	Stormy = "stormy"
+	Snowy  = "snowy"
+)
+
+type weatherChance struct {
+	Weather string
+	Chance  float64
+}
+
+// chances of every weather but sunny in each season, sunny takes the rest
+var seasonWeather = map[string][]weatherChance{
+	"spring": {{Rainy, 0.25}, {Stormy, 0.05}},
+	"summer": {{Rainy, 0.1}, {Stormy, 0.15}},
+	"fall":   {{Rainy, 0.3}, {Stormy, 0.05}},
+	"winter": {{Snowy, 0.4}},
+}
+
+func rollWeather(season string, rng *rand.Rand) string {
+	roll := rng.Float64()
+	for _, w := range seasonWeather[season] {
+		if roll < w.Chance {
+			return w.Weather
+		}
+		roll -= w.Chance
+	}
+	return Sunny
+}
+
+func knownWeather(weather string) string {
+	switch weather {
+	case Rainy, Stormy, Snowy:
+		return weather
+	}
+	return Sunny
+}
+
+// IsRaining is true for weathers that water the farm
+func IsRaining(weather string) bool {
+	return weather == Rainy || weather == Stormy
+}
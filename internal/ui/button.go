@@ -15,13 +15,16 @@ const (
 type Button struct {
 	Rect           rl.Rectangle
 	State          ButtonState
-	pressedCounter int
+	pressedCounter float32
 }
 
-func (b *Button) Update() {
+// how long a button shows as pressed, in seconds
+const pressedDuration float32 = 0.25
+
+func (b *Button) Update(dt float32) {
 	if b.State == BtnPressed {
 		if b.pressedCounter > 0 {
-			b.pressedCounter = max(0, b.pressedCounter-1)
+			b.pressedCounter = max(0, b.pressedCounter-dt)
 		} else {
 			b.State = BtnEnabled
 		}
@@ -33,7 +36,7 @@ func (b *Button) Press() bool {
 		return false
 	}
 	b.State = BtnPressed
-	b.pressedCounter = 15
+	b.pressedCounter = pressedDuration
 	return true
 }
 
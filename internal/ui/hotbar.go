@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+// HotbarUI draws the hotbar along the bottom of the screen
+type HotbarUI struct {
+	container rl.Rectangle
+	padding   float32
+	slotsize  float32
+	images    map[string]rl.Texture2D
+}
+
+func NewHotbarUI(screenWidth float32, screenHeight float32, tilesize float32, images map[string]rl.Texture2D) HotbarUI {
+	const padding float32 = 12.0
+	slotsize := tilesize
+	w := (slotsize+padding)*items.HotbarSize + padding
+	h := slotsize + padding*2
+	return HotbarUI{
+		container: rl.NewRectangle(screenWidth*0.5-w*0.5, screenHeight-h-10, w, h),
+		padding:   padding,
+		slotsize:  slotsize,
+		images:    images,
+	}
+}
+
+func (ui *HotbarUI) SlotRect(i int) rl.Rectangle {
+	x := ui.container.X + ui.padding + (ui.padding+ui.slotsize)*float32(i)
+	return rl.NewRectangle(x, ui.container.Y+ui.padding, ui.slotsize, ui.slotsize)
+}
+
+// Draw shows every slot with the key that selects it and frames the selected one
+func (ui *HotbarUI) Draw(hotbar *items.Hotbar, inventory *items.Inventory, uiAssets map[string]rl.Texture2D, tilescale float32) {
+	lineColor := rl.NewColor(rl.Beige.R-20, rl.Beige.G-20, rl.Beige.B-20, 255)
+	rl.DrawRectangleRec(ui.container, rl.Beige)
+	rl.DrawRectangleLinesEx(ui.container, 2, lineColor)
+	for i := range items.HotbarSize {
+		rect := ui.SlotRect(i)
+		if item, ok := hotbar.Item(inventory, i); ok {
+			DrawItem(rect, ui.images[item.ID], tilescale, item.Quantity)
+			DrawQuality(rect, item.Quality)
+		} else {
+			rl.DrawRectangleRec(rect, rl.Brown)
+		}
+		rl.DrawText(strconv.Itoa((i+1)%items.HotbarSize), int32(rect.X)+4, int32(rect.Y)+2, 15, rl.White)
+	}
+	drawSlotSelection(ui.SlotRect(hotbar.Selected), tilescale, uiAssets, 255)
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+type InventoryUI struct {
+	container rl.Rectangle
+	padding   float32
+	slotsize  float32
+	colcount  float32
+	Selected  items.Stack
+	hover     items.Stack
+	images    map[string]rl.Texture2D
+}
+
+func NewInventoryUI(screenWidth float32, screenHeight float32, tilesize float32, images map[string]rl.Texture2D) InventoryUI {
+	var w float32 = 800.0
+	var h float32 = 600.0
+	container := rl.NewRectangle(screenWidth*0.5-w*0.5, screenHeight*0.5-h*0.5, w, h)
+	const padding float32 = 28.0
+	slotsize := tilesize
+	colcount := float32(math.Floor(float64(container.Width / (slotsize + padding))))
+	return InventoryUI{
+		container: container,
+		padding:   padding,
+		slotsize:  slotsize,
+		colcount:  colcount,
+		images:    images,
+	}
+
+}
+
+func stackOf(item items.InventoryItem) items.Stack {
+	return items.Stack{ID: item.ID, Quality: item.Quality}
+}
+
+// stackName is the item name with its quality, e.g. "Carrot (gold)"
+func stackName(item items.InventoryItem) string {
+	if item.Quality == items.Normal {
+		return item.Name
+	}
+	return fmt.Sprintf("%s (%s)", item.Name, item.Quality)
+}
+
+func (ui *InventoryUI) ItemClick(inventory *items.Inventory, mpos rl.Vector2) {
+	for i, item := range inventory.Items() {
+		irect := itemSlotRect(ui.container, i, ui.padding, ui.slotsize, ui.colcount)
+		if rl.CheckCollisionPointRec(mpos, irect) {
+			ui.Selected = stackOf(item)
+		}
+	}
+}
+
+func (ui *InventoryUI) ItemHover(inventory *items.Inventory, mpos rl.Vector2) {
+	hovered := false
+	for i, item := range inventory.Items() {
+		irect := itemSlotRect(ui.container, i, ui.padding, ui.slotsize, ui.colcount)
+		if rl.CheckCollisionPointRec(mpos, irect) && stackOf(item) != ui.Selected {
+			hovered = true
+			ui.hover = stackOf(item)
+		}
+	}
+	if !hovered {
+		ui.hover = items.Stack{}
+	}
+}
+
+func (ui *InventoryUI) Draw(inventory *items.Inventory, uiAssets map[string]rl.Texture2D, tilescale float32) {
+	lineColor := rl.NewColor(rl.Beige.R-20, rl.Beige.G-20, rl.Beige.B-20, 255)
+	rl.DrawRectangleRec(ui.container, rl.Beige)
+	rl.DrawRectangleLinesEx(ui.container, 2, lineColor)
+	rl.DrawText("Inventory", int32(ui.container.X)+20, int32(ui.container.Y)+10, 30, rl.White)
+	items := inventory.Items()
+	inventoryIdx := -1
+	padding := ui.padding
+	imgScale := tilescale
+	for i, item := range items {
+		rect := itemSlotRect(ui.container, i, padding, ui.slotsize, ui.colcount)
+		DrawItem(rect, ui.images[item.ID], imgScale, item.Quantity)
+		DrawQuality(rect, item.Quality)
+		if ui.Selected == stackOf(item) {
+			inventoryIdx = i
+			drawSlotSelection(rect, tilescale, uiAssets, 255)
+		} else if ui.hover == stackOf(item) {
+			drawSlotSelection(rect, tilescale, uiAssets, 100)
+		}
+	}
+	if inventoryIdx >= 0 {
+
+		// name
+		descRect := rl.NewRectangle(ui.container.X+padding, ui.container.Y+ui.container.Height-padding-180, ui.container.Width-padding*2, 180)
+		rl.DrawRectangleRec(descRect, rl.White)
+		rl.DrawText(stackName(items[inventoryIdx]), int32(descRect.X+padding), int32(descRect.Y+padding*0.5), 25, rl.Black)
+
+		// price
+		priceText := fmt.Sprintf("$%d", items[inventoryIdx].SellPrice)
+		priceTextW := rl.MeasureText(priceText, 25)
+		rl.DrawText(priceText, int32(descRect.X+descRect.Width-padding-float32(priceTextW)), int32(descRect.Y+padding*0.5), 25, rl.DarkGray)
+
+		// description
+		DrawMultilineText(
+			items[inventoryIdx].Description,
+			rl.NewVector2(descRect.X+padding, descRect.Y+padding*2),
+			20,
+			int32(descRect.Width-6*padding),
+			8,
+		)
+		rl.DrawText("Press 1 to 0 to put it on the hotbar", int32(descRect.X), int32(descRect.Y+descRect.Height)+6, 15, rl.Gray)
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/strip"
+)
+
+func cropStrip(img strip.StripImg, idx int) rl.Texture2D {
+	image := rl.LoadImageFromTexture(img.Img)
+	defer rl.UnloadImage(image)
+	rl.ImageCrop(image, img.SrcRects[idx])
+	return rl.LoadTextureFromImage(image)
+}
+
+// LoadItemImages crops the icon of every item out of its strip asset, keyed by item id
+func LoadItemImages(allItems []items.Item, assets map[string]strip.StripImg) map[string]rl.Texture2D {
+	res := map[string]rl.Texture2D{}
+	for _, item := range allItems {
+		if img, ok := assets[item.Sprite]; ok && item.Frame < img.StripCount {
+			res[item.ID] = cropStrip(img, item.Frame)
+		}
+	}
+	return res
+}
+
+func DrawItem(rect rl.Rectangle, img rl.Texture2D, scale float32, quantity int) {
+	rl.DrawRectangleRec(rect, rl.Brown)
+	slotsize := rect.Width
+	tx := rect.X + slotsize*0.5 - float32(img.Width)*scale*0.5
+	ty := rect.Y + slotsize*0.5 - float32(img.Height)*scale*0.5
+	rl.DrawTextureEx(img, rl.NewVector2(tx, ty), 0, scale, rl.White)
+
+	// quantity
+	var qfontsize int32 = 15
+	qText := strconv.Itoa(quantity)
+	qWidth := rl.MeasureText(qText, qfontsize) + 5
+	rl.DrawText(qText, int32(rect.X+slotsize)-qWidth, int32(rect.Y+slotsize)-qfontsize, qfontsize, rl.White)
+}
+
+// DrawQuality marks a slot holding silver or gold items with a star of that color
+func DrawQuality(rect rl.Rectangle, quality items.Quality) {
+	var color rl.Color
+	switch quality {
+	case items.Silver:
+		color = rl.LightGray
+	case items.Gold:
+		color = rl.Gold
+	default:
+		return
+	}
+	radius := rect.Width * 0.15
+	center := rl.NewVector2(rect.X+radius+3, rect.Y+rect.Height-radius-3)
+	rl.DrawPoly(center, 5, radius+1, -90, rl.Black)
+	rl.DrawPoly(center, 5, radius, -90, color)
+}
+
+func drawSlotSelection(rect rl.Rectangle, scale float32, uiAssets map[string]rl.Texture2D, alpha uint8) {
+	shift := rect.Width * 0.25
+	stl := rl.NewVector2(rect.X-shift, rect.Y-shift)
+	str := rl.NewVector2(rect.X+rect.Width-shift, rect.Y-shift)
+	sbl := rl.NewVector2(rect.X-shift, rect.Y+rect.Height-shift)
+	sbr := rl.NewVector2(rect.X+rect.Width-shift, rect.Y+rect.Height-shift)
+	tint := rl.NewColor(255, 255, 255, alpha)
+	rl.DrawTextureEx(uiAssets["selectbox_tl"], stl, 0, scale, tint)
+	rl.DrawTextureEx(uiAssets["selectbox_tr"], str, 0, scale, tint)
+	rl.DrawTextureEx(uiAssets["selectbox_br"], sbr, 0, scale, tint)
+	rl.DrawTextureEx(uiAssets["selectbox_bl"], sbl, 0, scale, tint)
+}
+
+func itemSlotRect(container rl.Rectangle, i int, padding float32, slotsize float32, colCount float32) rl.Rectangle {
+	x := container.X + padding + ((padding + slotsize) * (float32(math.Mod(float64(i), float64(colCount)))))
+	y := container.Y + padding*2 + ((padding + slotsize) * (float32(math.Floor(float64(i) / float64(colCount)))))
+	rect := rl.NewRectangle(x, y, slotsize, slotsize)
+	return rect
+}
+
+func DrawMultilineText(text string, topleft rl.Vector2, fontsize int32, maxwidth int32, linespacing int32) {
+	words := strings.Split(text, " ")
+	startX := 0
+	endX := 0
+	y := 0
+	startY := topleft.Y
+	maxWidth := maxwidth
+	for endX < len(words) {
+		line := strings.Join(words[startX:endX+1], " ")
+		w := rl.MeasureText(line, fontsize)
+		if endX >= len(words)-1 || w >= maxWidth {
+			rl.DrawText(line, int32(topleft.X), int32(startY)+int32(y)*fontsize+int32(y)*linespacing, fontsize, rl.Gray)
+			startX = endX + 1
+			y += 1
+		}
+		endX += 1
+	}
+}
@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/items"
+)
+
+type Selection struct {
+	side    string
+	id      string
+	quality items.Quality
+}
+
+type ShopUI struct {
+	container          rl.Rectangle
+	inventoryContainer rl.Rectangle
+	shopContainer      rl.Rectangle
+	padding            float32
+	slotsize           float32
+	colcount           float32
+	selection          Selection
+	hoverId            Selection
+	selectionRect      rl.Rectangle
+	hoverRect          rl.Rectangle
+	footerContainer    rl.Rectangle
+	button             TextButton
+	increaseButton     ImgButton
+	decreaseButton     ImgButton
+	quantity           int
+	images             map[string]rl.Texture2D
+}
+
+type ShopActionKind int
+
+const (
+	ShopNone ShopActionKind = iota
+	ShopBuy
+	ShopSell
+)
+
+// ShopAction is a trade the player confirmed, the caller decides whether it goes through
+type ShopAction struct {
+	Kind ShopActionKind
+	// item id
+	Item     string
+	Quality  items.Quality
+	Quantity int
+}
+
+func NewShopUI(screenSize rl.Vector2, tilesize float32, uiAssets map[string]rl.Texture2D, images map[string]rl.Texture2D) ShopUI {
+	var w float32 = 1000
+	var h float32 = 600
+
+	container := rl.NewRectangle(screenSize.X*0.5-w*0.5, screenSize.Y*0.5-h*0.5, w, h)
+
+	const padding float32 = 28.0
+	slotsize := tilesize
+	colcount := 6
+
+	sectionWidth := padding*float32(colcount) + slotsize*float32(colcount)
+	inventoryContainer := rl.NewRectangle(container.X, container.Y, sectionWidth, container.Height)
+
+	shopX := container.X + container.Width - sectionWidth - padding
+	shopContainer := rl.NewRectangle(shopX, container.Y, container.Width, container.Height)
+	footerContainer := rl.NewRectangle(container.X, container.Y+container.Height-150, container.Width, 150)
+	btnRect := rl.NewRectangle(
+		footerContainer.X+footerContainer.Width-padding-150,
+		footerContainer.Y+footerContainer.Height-padding*0.5-40,
+		150,
+		40,
+	)
+	btn := NewTextButton(btnRect, "BUY", 20, rl.Blue)
+
+	rightArrow := uiAssets["arrow_right"]
+	leftArrow := uiAssets["arrow_left"]
+	buttonScale := tilesize / float32(rightArrow.Height) * 0.6
+
+	increaseButton := NewImgButton(
+		rl.NewVector2(
+			btn.Rect.X+btn.Rect.Width-float32(rightArrow.Width)*buttonScale,
+			btn.Rect.Y-padding*0.25-float32(rightArrow.Height)*buttonScale,
+		),
+		rightArrow,
+		buttonScale,
+	)
+
+	decreaseButton := NewImgButton(
+		rl.NewVector2(
+			btn.Rect.X,
+			btn.Rect.Y-padding*0.25-float32(leftArrow.Height)*buttonScale,
+		),
+		leftArrow,
+		buttonScale,
+	)
+	return ShopUI{
+		container:          container,
+		padding:            padding,
+		slotsize:           slotsize,
+		colcount:           float32(colcount),
+		inventoryContainer: inventoryContainer,
+		shopContainer:      shopContainer,
+		footerContainer:    footerContainer,
+		button:             btn,
+		increaseButton:     increaseButton,
+		decreaseButton:     decreaseButton,
+		quantity:           1,
+		images:             images,
+	}
+}
+
+// Click handles selection and quantity changes, and returns the trade to perform when the buy/sell button was pressed
+func (u *ShopUI) Click(mpos rl.Vector2, inventory *items.Inventory, shop *items.Shop) ShopAction {
+	for i, item := range inventory.Items() {
+		rect := itemSlotRect(u.inventoryContainer, i, u.padding, u.slotsize, u.colcount)
+		if rl.CheckCollisionPointRec(mpos, rect) {
+			u.selection.id = item.ID
+			u.selection.quality = item.Quality
+			u.selection.side = "inventory"
+			u.selectionRect = rect
+			u.button.SetText("SELL")
+			u.button.BgColor = rl.Red
+			u.quantity = 1
+			return ShopAction{Kind: ShopNone}
+		}
+	}
+	for i, item := range shop.Items {
+		rect := itemSlotRect(u.shopContainer, i, u.padding, u.slotsize, u.colcount)
+		if rl.CheckCollisionPointRec(mpos, rect) {
+			u.selection.id = item.ID
+			u.selection.quality = items.Normal
+			u.selection.side = "shop"
+			u.selectionRect = rect
+			u.button.SetText("BUY")
+			u.button.BgColor = rl.NewColor(30, 144, 255, 255)
+			u.quantity = 1
+			return ShopAction{Kind: ShopNone}
+		}
+	}
+	if rl.CheckCollisionPointRec(mpos, u.button.Rect) && u.selection.id != "" {
+		u.button.Press()
+		// buy or sell
+		if u.selection.side == "shop" {
+			return ShopAction{Kind: ShopBuy, Item: u.selection.id, Quantity: u.quantity}
+		} else if u.selection.side == "inventory" {
+			return ShopAction{Kind: ShopSell, Item: u.selection.id, Quality: u.selection.quality, Quantity: u.quantity}
+		}
+	}
+	if rl.CheckCollisionPointRec(mpos, u.increaseButton.Rect) {
+		u.increaseButton.Press()
+		u.quantity = min(u.quantity+1, u.maxQuantity(inventory, shop))
+	}
+	if rl.CheckCollisionPointRec(mpos, u.decreaseButton.Rect) {
+		u.decreaseButton.Press()
+		u.quantity = max(1, u.quantity-1)
+	}
+	return ShopAction{Kind: ShopNone}
+}
+
+func (u *ShopUI) maxQuantity(inventory *items.Inventory, shop *items.Shop) int {
+	if u.selection.side == "inventory" {
+		if item, ok := inventory.Stack(u.selection.id, u.selection.quality); ok {
+			return item.Quantity
+		}
+	}
+	if u.selection.side == "shop" {
+		if item, ok := shop.Item(u.selection.id); ok {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// Update clears the selection once the selected item runs out, and keeps the quantity within stock
+func (u *ShopUI) Update(dt float32, inventory *items.Inventory, shop *items.Shop) {
+	u.button.Update(dt)
+	u.increaseButton.Update(dt)
+	u.decreaseButton.Update(dt)
+	if u.selection.id == "" {
+		return
+	}
+	if maxQuantity := u.maxQuantity(inventory, shop); maxQuantity == 0 {
+		u.selection.id = ""
+		u.quantity = 1
+	} else {
+		u.quantity = min(u.quantity, maxQuantity)
+	}
+}
+
+func (u *ShopUI) Draw(shop *items.Shop, inventory *items.Inventory, uiAssets map[string]rl.Texture2D, tilescale float32) {
+	lineColor := rl.NewColor(rl.Beige.R-20, rl.Beige.G-20, rl.Beige.B-20, 255)
+	rl.DrawRectangleRec(u.container, rl.Beige)
+	rl.DrawRectangleLinesEx(u.container, 2, lineColor)
+
+	u.drawInventory(inventory, u.inventoryContainer, tilescale)
+
+	midX := u.container.X + u.container.Width*0.5
+	rl.DrawLineEx(rl.NewVector2(midX, u.container.Y), rl.NewVector2(midX, u.container.Y+u.container.Height), 2, lineColor)
+
+	// shop
+	u.drawShop(shop, u.shopContainer, tilescale)
+	priceText := fmt.Sprintf("%.0f", inventory.Deposit())
+	priceTextWidth := rl.MeasureText(priceText, 22)
+	rl.DrawText(priceText, int32(u.container.X+u.container.Width-u.padding)-priceTextWidth, int32(u.container.Y)+15, 22, rl.White)
+
+	if u.selection.id != "" {
+		drawSlotSelection(u.selectionRect, tilescale, uiAssets, 255)
+		rl.DrawRectangleRec(u.footerContainer, rl.White)
+		rl.DrawRectangleLinesEx(u.footerContainer, 2, lineColor)
+		// name
+		if u.selection.side == "shop" {
+			if idx := slices.IndexFunc(shop.Items, func(x items.ShopItem) bool {
+				return x.ID == u.selection.id
+			}); idx != -1 {
+				item := shop.Items[idx]
+
+				var priceColor rl.Color
+				totalPrice := float32(item.BuyPrice * u.quantity)
+				materials, enough := materialsText(item.Item, u.quantity, inventory)
+				if totalPrice <= inventory.Deposit() && enough {
+					priceColor = rl.Black
+				} else {
+					priceColor = rl.Red
+				}
+				btn := u.button
+				if btn.State != BtnDisabled && (totalPrice > inventory.Deposit() || !enough) {
+					btn.State = BtnDisabled
+				}
+				drawShopFooter(
+					u.footerContainer,
+					item.Name,
+					item.Description,
+					float32(item.BuyPrice),
+					materials,
+					float32(u.quantity),
+					priceColor,
+					u.padding,
+					&btn,
+					&u.increaseButton,
+					&u.decreaseButton,
+				)
+			}
+		} else if u.selection.side == "inventory" {
+			if idx := slices.IndexFunc(inventory.Items(), func(x items.InventoryItem) bool {
+				return x.ID == u.selection.id && x.Quality == u.selection.quality
+			}); idx != -1 {
+				item := inventory.Items()[idx]
+				drawShopFooter(
+					u.footerContainer,
+					stackName(item),
+					item.Description,
+					float32(item.SellPrice),
+					"",
+					float32(u.quantity),
+					rl.Black,
+					u.padding,
+					&u.button,
+					&u.increaseButton,
+					&u.decreaseButton,
+				)
+			}
+
+		}
+	}
+	if u.hoverId.id != "" && u.hoverId != u.selection {
+		drawSlotSelection(u.hoverRect, tilescale, uiAssets, 100)
+	}
+
+}
+
+// materialsText lists the materials quantity items are made of, enough is false when the inventory is short of any
+func materialsText(item items.Item, quantity int, inventory *items.Inventory) (string, bool) {
+	ids := []string{}
+	for id := range item.Materials {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	parts := []string{}
+	enough := true
+	for _, id := range ids {
+		n := item.Materials[id] * quantity
+		name := id
+		if stack, ok := inventory.Item(id); ok {
+			name = stack.Name
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+		enough = enough && inventory.Count(id) >= n
+	}
+	return strings.Join(parts, ", "), enough
+}
+
+func drawShopFooter(container rl.Rectangle, name string, description string, price float32, materials string, quantity float32, priceColor rl.Color, padding float32, button *TextButton, increaseButton *ImgButton, decreaseButton *ImgButton) {
+	// name
+	rl.DrawText(name, int32(container.X+padding), int32(container.Y+padding), 20, rl.Black)
+	// description
+	descRect := rl.NewRectangle(container.X, container.Y+container.Height-180, container.Width-padding*2, 180)
+	DrawMultilineText(
+		description,
+		rl.NewVector2(descRect.X+padding, descRect.Y+padding*3.5),
+		19,
+		int32(descRect.Width-5*padding-button.Rect.Width),
+		8,
+	)
+
+	// price
+	totalPrice := price * quantity
+	priceText := fmt.Sprintf("$%0.f", totalPrice)
+	var priceFontsize int32 = 20
+	priceTextWidth := rl.MeasureText(priceText, priceFontsize)
+	rl.DrawText(
+		priceText,
+		int32(container.X+container.Width-padding-float32(priceTextWidth)),
+		int32(container.Y+padding),
+		priceFontsize,
+		priceColor,
+	)
+	if materials != "" {
+		text := "+ " + materials
+		textWidth := rl.MeasureText(text, 18)
+		rl.DrawText(
+			text,
+			int32(container.X+container.Width-padding-float32(textWidth)),
+			int32(container.Y+padding)+priceFontsize+6,
+			18,
+			priceColor,
+		)
+	}
+
+	// quantity
+	quantityRect := rl.NewRectangle(
+		button.Rect.X+button.Rect.Width*0.5-35,
+		button.Rect.Y-padding*0.25-float32(increaseButton.Rect.Height),
+		70,
+		30,
+	)
+	rl.DrawRectangleRec(quantityRect, rl.RayWhite)
+	qText := fmt.Sprintf("%0.f", quantity)
+	var qFontSize int32 = 18
+	qTextWidth := rl.MeasureText(qText, qFontSize)
+	rl.DrawText(
+		qText,
+		int32(quantityRect.X+quantityRect.Width*0.5-float32(qTextWidth)*0.5),
+		int32(quantityRect.Y+quantityRect.Height*0.5-float32(qFontSize)*0.5),
+		qFontSize,
+		rl.Black,
+	)
+
+	// increase button
+	increaseButton.Draw()
+
+	// decrease button
+	decreaseButton.Draw()
+
+	button.Draw()
+}
+
+func (u *ShopUI) ItemHover(mpos rl.Vector2, inventory *items.Inventory, shop *items.Shop) {
+	u.hoverId.id = ""
+	u.hoverId.side = ""
+	for i, item := range inventory.Items() {
+		rect := itemSlotRect(u.inventoryContainer, i, u.padding, u.slotsize, u.colcount)
+		if rl.CheckCollisionPointRec(mpos, rect) {
+			u.hoverId.id = item.ID
+			u.hoverId.quality = item.Quality
+			u.hoverId.side = "inventory"
+			u.hoverRect = rect
+			return
+		}
+	}
+	for i, item := range shop.Items {
+		rect := itemSlotRect(u.shopContainer, i, u.padding, u.slotsize, u.colcount)
+		if rl.CheckCollisionPointRec(mpos, rect) {
+			u.hoverId.id = item.ID
+			u.hoverId.quality = items.Normal
+			u.hoverId.side = "shop"
+			u.hoverRect = rect
+			return
+		}
+	}
+}
+
+func (u *ShopUI) drawInventory(inventory *items.Inventory, container rl.Rectangle, scale float32) {
+	rl.DrawText("Inventory", int32(container.X)+20, int32(container.Y)+10, 30, rl.White)
+	items := inventory.Items()
+	padding := u.padding
+	for i, item := range items {
+		rect := itemSlotRect(container, i, padding, u.slotsize, u.colcount)
+		DrawItem(rect, u.images[item.ID], scale, item.Quantity)
+		DrawQuality(rect, item.Quality)
+	}
+}
+
+func (u *ShopUI) drawShop(shop *items.Shop, container rl.Rectangle, scale float32) {
+	rl.DrawText(shop.Name(), int32(container.X)+20, int32(container.Y)+10, 30, rl.White)
+	items := shop.Items
+	padding := u.padding
+	for i, item := range items {
+		rect := itemSlotRect(container, i, padding, u.slotsize, u.colcount)
+		DrawItem(rect, u.images[item.ID], scale, item.Quantity)
+	}
+}
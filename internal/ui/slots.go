@@ -0,0 +1,337 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/save"
+)
+
+type SlotActionKind int
+
+const (
+	SlotNone SlotActionKind = iota
+	SlotNew
+	SlotLoad
+	SlotDuplicate
+	SlotDelete
+	SlotJoin
+)
+
+type SlotAction struct {
+	Kind     SlotActionKind
+	SlotID   string
+	FarmName string
+	// host address for SlotJoin
+	Addr string
+}
+
+type slotRow struct {
+	info            save.SlotInfo
+	loadButton      TextButton
+	duplicateButton TextButton
+	deleteButton    TextButton
+}
+
+type hostRow struct {
+	entry      lan.HostEntry
+	joinButton TextButton
+}
+
+type SlotPicker struct {
+	container     rl.Rectangle
+	padding       float32
+	rowHeight     float32
+	rows          []slotRow
+	scroll        int
+	visibleRows   int
+	pendingDelete string
+	farmName      string
+	nameRect      rl.Rectangle
+	createButton  TextButton
+	lanButton     TextButton
+	showHosts     bool
+	hosts         []hostRow
+}
+
+const maxFarmNameLen = 20
+
+func NewSlotPicker(screenSize rl.Vector2, slots []save.SlotInfo) SlotPicker {
+	var w float32 = 800
+	var h float32 = 600
+	container := rl.NewRectangle(screenSize.X*0.5-w*0.5, screenSize.Y*0.5-h*0.5, w, h)
+	const padding float32 = 28
+	const rowHeight float32 = 70
+
+	footerY := container.Y + container.Height - padding - 40
+	nameRect := rl.NewRectangle(container.X+padding, footerY, container.Width-padding*3-150, 40)
+	createButton := NewTextButton(
+		rl.NewRectangle(container.X+container.Width-padding-150, footerY, 150, 40),
+		"NEW FARM",
+		20,
+		rl.NewColor(30, 144, 255, 255),
+	)
+	lanButton := NewTextButton(
+		rl.NewRectangle(container.X+container.Width-padding-150, container.Y+10, 150, 36),
+		"LAN GAMES",
+		18,
+		rl.DarkGreen,
+	)
+	visibleRows := int((footerY - padding - (container.Y + padding*2.5)) / rowHeight)
+
+	p := SlotPicker{
+		container:    container,
+		padding:      padding,
+		rowHeight:    rowHeight,
+		visibleRows:  visibleRows,
+		nameRect:     nameRect,
+		createButton: createButton,
+		lanButton:    lanButton,
+		hosts:        []hostRow{},
+	}
+	p.SetSlots(slots)
+	return p
+}
+
+func (p *SlotPicker) SetSlots(slots []save.SlotInfo) {
+	p.rows = []slotRow{}
+	p.pendingDelete = ""
+	for _, s := range slots {
+		p.rows = append(p.rows, slotRow{
+			info:            s,
+			loadButton:      NewTextButton(rl.Rectangle{}, "LOAD", 18, rl.NewColor(30, 144, 255, 255)),
+			duplicateButton: NewTextButton(rl.Rectangle{}, "COPY", 18, rl.DarkGreen),
+			deleteButton:    NewTextButton(rl.Rectangle{}, "DELETE", 18, rl.Red),
+		})
+	}
+	p.scroll = max(0, min(p.scroll, len(p.rows)-p.visibleRows))
+	p.layout()
+}
+
+// SetHosts refreshes the LAN games list, rows of hosts still announcing keep their button state
+func (p *SlotPicker) SetHosts(entries []lan.HostEntry) {
+	hosts := []hostRow{}
+	for _, e := range entries {
+		row := hostRow{
+			entry:      e,
+			joinButton: NewTextButton(rl.Rectangle{}, "JOIN", 18, rl.NewColor(30, 144, 255, 255)),
+		}
+		for _, old := range p.hosts {
+			if old.entry.Addr == e.Addr {
+				row.joinButton = old.joinButton
+			}
+		}
+		hosts = append(hosts, row)
+	}
+	p.hosts = hosts
+	p.layout()
+}
+
+func (p *SlotPicker) layout() {
+	const btnWidth float32 = 90
+	const btnHeight float32 = 36
+	for i := range p.hosts {
+		rowRect := p.rowRect(i)
+		p.hosts[i].joinButton.Rect = rl.NewRectangle(
+			rowRect.X+rowRect.Width-p.padding*0.5-btnWidth,
+			rowRect.Y+rowRect.Height*0.5-btnHeight*0.5,
+			btnWidth,
+			btnHeight,
+		)
+	}
+	for i := range p.rows {
+		row := &p.rows[i]
+		rowRect := p.rowRect(i - p.scroll)
+		y := rowRect.Y + rowRect.Height*0.5 - btnHeight*0.5
+		x := rowRect.X + rowRect.Width - p.padding*0.5 - btnWidth
+		row.deleteButton.Rect = rl.NewRectangle(x, y, btnWidth, btnHeight)
+		x -= btnWidth + p.padding*0.5
+		row.duplicateButton.Rect = rl.NewRectangle(x, y, btnWidth, btnHeight)
+		x -= btnWidth + p.padding*0.5
+		row.loadButton.Rect = rl.NewRectangle(x, y, btnWidth, btnHeight)
+	}
+}
+
+func (p *SlotPicker) rowRect(i int) rl.Rectangle {
+	return rl.NewRectangle(
+		p.container.X+p.padding,
+		p.container.Y+p.padding*2.5+float32(i)*p.rowHeight,
+		p.container.Width-p.padding*2,
+		p.rowHeight-p.padding*0.25,
+	)
+}
+
+func (p *SlotPicker) isVisible(i int) bool {
+	return i >= p.scroll && i < p.scroll+p.visibleRows
+}
+
+// Update handles typing, scrolling and clicks, and returns what the player picked this frame
+func (p *SlotPicker) Update(dt float32, mpos rl.Vector2) SlotAction {
+	p.createButton.Update(dt)
+	p.lanButton.Update(dt)
+	for i := range p.rows {
+		p.rows[i].loadButton.Update(dt)
+		p.rows[i].duplicateButton.Update(dt)
+		p.rows[i].deleteButton.Update(dt)
+	}
+	for i := range p.hosts {
+		p.hosts[i].joinButton.Update(dt)
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) && rl.CheckCollisionPointRec(mpos, p.lanButton.Rect) && p.lanButton.Press() {
+		p.showHosts = !p.showHosts
+		if p.showHosts {
+			p.lanButton.SetText("FARMS")
+		} else {
+			p.lanButton.SetText("LAN GAMES")
+		}
+		return SlotAction{Kind: SlotNone}
+	}
+	if p.showHosts {
+		if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+			for i := range p.hosts {
+				if i >= p.visibleRows {
+					break
+				}
+				row := &p.hosts[i]
+				if rl.CheckCollisionPointRec(mpos, row.joinButton.Rect) && row.joinButton.Press() {
+					return SlotAction{Kind: SlotJoin, FarmName: row.entry.FarmName, Addr: row.entry.Addr}
+				}
+			}
+		}
+		return SlotAction{Kind: SlotNone}
+	}
+
+	for c := rl.GetCharPressed(); c > 0; c = rl.GetCharPressed() {
+		if c >= 32 && c < 127 && len(p.farmName) < maxFarmNameLen {
+			p.farmName += string(rune(c))
+		}
+	}
+	if rl.IsKeyPressed(rl.KeyBackspace) && len(p.farmName) > 0 {
+		p.farmName = p.farmName[:len(p.farmName)-1]
+	}
+
+	if wheel := rl.GetMouseWheelMove(); wheel != 0 {
+		p.scroll = max(0, min(p.scroll-int(wheel), len(p.rows)-p.visibleRows))
+		p.layout()
+	}
+
+	createPressed := rl.IsKeyPressed(rl.KeyEnter)
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+		if rl.CheckCollisionPointRec(mpos, p.createButton.Rect) {
+			createPressed = true
+		}
+		for i := range p.rows {
+			if !p.isVisible(i) {
+				continue
+			}
+			row := &p.rows[i]
+			if rl.CheckCollisionPointRec(mpos, row.loadButton.Rect) && row.loadButton.Press() {
+				return SlotAction{Kind: SlotLoad, SlotID: row.info.ID, FarmName: row.info.FarmName}
+			}
+			if rl.CheckCollisionPointRec(mpos, row.duplicateButton.Rect) && row.duplicateButton.Press() {
+				return SlotAction{Kind: SlotDuplicate, SlotID: row.info.ID}
+			}
+			if rl.CheckCollisionPointRec(mpos, row.deleteButton.Rect) && row.deleteButton.Press() {
+				// ask for a second click before removing a farm for good
+				if p.pendingDelete != row.info.ID {
+					p.cancelDelete()
+					p.pendingDelete = row.info.ID
+					row.deleteButton.SetText("SURE?")
+					continue
+				}
+				return SlotAction{Kind: SlotDelete, SlotID: row.info.ID}
+			}
+		}
+	}
+	if createPressed && p.farmName != "" && p.createButton.Press() {
+		name := p.farmName
+		p.farmName = ""
+		return SlotAction{Kind: SlotNew, FarmName: name}
+	}
+	return SlotAction{Kind: SlotNone}
+}
+
+func (p *SlotPicker) cancelDelete() {
+	for i := range p.rows {
+		if p.rows[i].info.ID == p.pendingDelete {
+			p.rows[i].deleteButton.SetText("DELETE")
+		}
+	}
+	p.pendingDelete = ""
+}
+
+func (p *SlotPicker) Draw() {
+	lineColor := rl.NewColor(rl.Beige.R-20, rl.Beige.G-20, rl.Beige.B-20, 255)
+	rl.DrawRectangleRec(p.container, rl.Beige)
+	rl.DrawRectangleLinesEx(p.container, 2, lineColor)
+	p.lanButton.Draw()
+	if p.showHosts {
+		p.drawHosts()
+		return
+	}
+	rl.DrawText("Farms", int32(p.container.X)+20, int32(p.container.Y)+10, 30, rl.White)
+
+	if len(p.rows) == 0 {
+		rl.DrawText("No saved farms yet", int32(p.container.X+p.padding), int32(p.container.Y+p.padding*2.5), 20, rl.Gray)
+	}
+	for i := range p.rows {
+		if !p.isVisible(i) {
+			continue
+		}
+		row := &p.rows[i]
+		rect := p.rowRect(i - p.scroll)
+		rl.DrawRectangleRec(rect, rl.White)
+		name := row.info.FarmName
+		if name == "" {
+			name = row.info.ID
+		}
+		rl.DrawText(name, int32(rect.X+p.padding*0.5), int32(rect.Y+8), 22, rl.Black)
+		details := fmt.Sprintf("%s   $%.0f   %s", calendar.DateOf(row.info.Day), row.info.Money, formatPlaytime(row.info.Playtime))
+		rl.DrawText(details, int32(rect.X+p.padding*0.5), int32(rect.Y+rect.Height-26), 18, rl.DarkGray)
+		row.loadButton.Draw()
+		row.duplicateButton.Draw()
+		row.deleteButton.Draw()
+	}
+
+	rl.DrawRectangleRec(p.nameRect, rl.RayWhite)
+	rl.DrawRectangleLinesEx(p.nameRect, 2, lineColor)
+	if p.farmName == "" {
+		rl.DrawText("Farm name", int32(p.nameRect.X+10), int32(p.nameRect.Y+10), 20, rl.LightGray)
+	} else {
+		rl.DrawText(p.farmName+"_", int32(p.nameRect.X+10), int32(p.nameRect.Y+10), 20, rl.Black)
+	}
+	createButton := p.createButton
+	if p.farmName == "" {
+		createButton.State = BtnDisabled
+	}
+	createButton.Draw()
+}
+
+func (p *SlotPicker) drawHosts() {
+	rl.DrawText("LAN games", int32(p.container.X)+20, int32(p.container.Y)+10, 30, rl.White)
+	if len(p.hosts) == 0 {
+		rl.DrawText("Searching for farms on the local network...", int32(p.container.X+p.padding), int32(p.container.Y+p.padding*2.5), 20, rl.Gray)
+	}
+	for i := range p.hosts {
+		if i >= p.visibleRows {
+			break
+		}
+		row := &p.hosts[i]
+		rect := p.rowRect(i)
+		rl.DrawRectangleRec(rect, rl.White)
+		rl.DrawText(row.entry.FarmName, int32(rect.X+p.padding*0.5), int32(rect.Y+8), 22, rl.Black)
+		details := fmt.Sprintf("%s   %d players   %s", calendar.DateOf(row.entry.Day), row.entry.Players, row.entry.Addr)
+		rl.DrawText(details, int32(rect.X+p.padding*0.5), int32(rect.Y+rect.Height-26), 18, rl.DarkGray)
+		row.joinButton.Draw()
+	}
+}
+
+func formatPlaytime(d time.Duration) string {
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	return fmt.Sprintf("%dh %02dm", h, m)
+}
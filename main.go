@@ -1,21 +1,32 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"log"
 	"math"
 	rand "math/rand/v2"
 	"slices"
 	"sort"
+	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/theanzy/farmsim/internal/anim"
+	"github.com/theanzy/farmsim/internal/calendar"
 	"github.com/theanzy/farmsim/internal/crop"
 	"github.com/theanzy/farmsim/internal/entity"
 	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
 	"github.com/theanzy/farmsim/internal/render"
+	"github.com/theanzy/farmsim/internal/replay"
+	"github.com/theanzy/farmsim/internal/save"
 	"github.com/theanzy/farmsim/internal/sfx"
+	"github.com/theanzy/farmsim/internal/sim"
 	"github.com/theanzy/farmsim/internal/strip"
 	"github.com/theanzy/farmsim/internal/tileset"
+	"github.com/theanzy/farmsim/internal/ui"
 	"github.com/theanzy/farmsim/internal/world"
 )
 
@@ -29,15 +40,14 @@ func (t Tile) Center(tilesize float32) rl.Vector2 {
 	return rl.NewVector2(t.Pos.X*tilesize+tilesize*0.5, t.Pos.Y*tilesize+0.5)
 }
 
-type FarmTile struct {
-	Pos rl.Vector2
-	// empty, digged, name of plant
-	IsWet   bool
-	State   string
-	CropAge int
+// strip asset of every tree kind of the tree_real layer
+var treeAssetNames = map[int]string{
+	4102: "tree_01",
+	4103: "tree_02",
 }
 
 type Tree struct {
+	// sapling, idle, shaking (falling), dead (a stump), uprooting, cleared
 	State         string
 	Img           strip.StripImg
 	Pos           rl.Vector2
@@ -48,10 +58,12 @@ type Tree struct {
 	hunkImg       rl.Texture2D
 	hunkSize      rl.Vector2
 	shakeDuration float32
-	WoodCount     int
+	// axe hits the tree or stump still takes, every hit wobbles it for a moment
+	Health         int
+	wobbleDuration float32
 }
 
-func NewTree(img strip.StripImg, hunkImg rl.Texture2D, cellpos rl.Vector2, tilesize float32, tilescale float32, woodcount int) Tree {
+func NewTree(img strip.StripImg, hunkImg rl.Texture2D, cellpos rl.Vector2, tilesize float32, tilescale float32) Tree {
 	size := rl.NewVector2(float32(img.Img.Width/int32(img.StripCount))*tilescale, float32(img.Img.Height)*tilescale)
 	pos := rl.NewVector2(cellpos.X*tilesize, cellpos.Y*tilesize)
 	hunkSize := rl.NewVector2(float32(hunkImg.Width)*tilescale, float32(hunkImg.Height)*tilescale)
@@ -65,7 +77,6 @@ func NewTree(img strip.StripImg, hunkImg rl.Texture2D, cellpos rl.Vector2, tiles
 		shakeDuration: 0,
 		hunkImg:       hunkImg,
 		hunkSize:      hunkSize,
-		WoodCount:     woodcount,
 	}
 }
 
@@ -83,28 +94,69 @@ func NewTreeHitbox(img strip.StripImg, pos rl.Vector2, tilesize float32, tilesca
 }
 
 func (t *Tree) Update(dt float32) {
-	if t.State == "shaking" {
+	t.wobbleDuration = max(t.wobbleDuration-100*dt, 0)
+	if t.State == "shaking" || t.State == "uprooting" {
 		t.shakeDuration -= 100 * dt
 		if t.shakeDuration <= 0 {
 			t.shakeDuration = 0
-			t.State = "dead"
-		} else {
-			t.frame += dt * 5
-			if t.frame >= float32(t.Img.StripCount) {
-				t.frame = 0
+			if t.State == "shaking" {
+				t.State = "dead"
+			} else {
+				t.State = "cleared"
 			}
 		}
-
+	}
+	if t.State == "shaking" || t.wobbleDuration > 0 {
+		t.frame += dt * 5
+		if t.frame >= float32(t.Img.StripCount) {
+			t.frame = 0
+		}
+	} else {
+		t.frame = 0
 	}
 }
 
+// Shake fells the tree, it shakes for duration before turning into a stump
 func (t *Tree) Shake(duration float32) {
 	t.State = "shaking"
 	t.shakeDuration = duration
 }
 
+// Uproot chops out the stump, it jitters for duration before it is gone
+func (t *Tree) Uproot(duration float32) {
+	t.State = "uprooting"
+	t.shakeDuration = duration
+}
+
+// Wobble shows an axe hit that did not fell the tree or clear the stump
+func (t *Tree) Wobble(duration float32) {
+	t.wobbleDuration = duration
+}
+
+// CanChop is true for standing trees and stumps
+func (t *Tree) CanChop() bool {
+	return t.State == "idle" || t.State == "dead"
+}
+
+// Blocks is true while the tree or its stump stands in the way, saplings are walked over
+func (t *Tree) Blocks() bool {
+	return t.State != "sapling" && t.State != "cleared"
+}
+
 func (t *Tree) Draw(offset rl.Vector2) {
-	if t.State == "dead" {
+	switch t.State {
+	case "cleared":
+		return
+	case "sapling":
+		// half the size of the tree, standing on its trunk
+		size := rl.Vector2Scale(t.Size, 0.5)
+		dest := rl.NewRectangle(t.Pos.X+t.Size.X*0.5-size.X*0.5-offset.X, t.Pos.Y+t.Size.Y-size.Y-offset.Y, size.X, size.Y)
+		rl.DrawTexturePro(t.Img.Img, t.Img.SrcRects[0], dest, rl.NewVector2(0, 0), 0, rl.White)
+		return
+	case "dead", "uprooting":
+		if t.State == "uprooting" || t.wobbleDuration > 0 {
+			offset.X += float32(math.Sin(float64(t.shakeDuration+t.wobbleDuration))) * 2
+		}
 		hunkSize := t.hunkSize
 		x := t.Pos.X + t.Size.X*0.5 - hunkSize.X*0.5 - offset.X
 		y := t.Pos.Y + t.Size.Y - hunkSize.Y - offset.Y
@@ -128,20 +180,79 @@ func (t *Tree) Draw(offset rl.Vector2) {
 	// rl.DrawRectangleRec(hitbox, rl.Red)
 }
 
+// offset of every tile of the rocks layer from the top left corner of its rock
+var rockTileOffset = map[int]rl.Vector2{
+	2993: rl.NewVector2(0, 0),
+	2994: rl.NewVector2(1, 0),
+	3057: rl.NewVector2(0, 1),
+	3058: rl.NewVector2(1, 1),
+}
+
+// Rock is a boulder of 2x2 tiles, it cracks for a moment once mined and then disappears
+type Rock struct {
+	State string
+	// top left cell
+	Cell          rl.Vector2
+	Tiles         []Tile
+	Hitbox        rl.Rectangle
+	Center        rl.Vector2
+	crackDuration float32
+}
+
+func NewRock(cellpos rl.Vector2, tilesize float32) Rock {
+	return Rock{
+		State: "idle",
+		Cell:  cellpos,
+		Tiles: []Tile{},
+		// only the bottom half blocks the way
+		Hitbox: rl.NewRectangle(cellpos.X*tilesize, (cellpos.Y+1)*tilesize, tilesize*2, tilesize),
+		Center: rl.NewVector2((cellpos.X+1)*tilesize, (cellpos.Y+1.5)*tilesize),
+	}
+}
+
+func (r *Rock) Update(dt float32) {
+	if r.State == "cracking" {
+		r.crackDuration -= 100 * dt
+		if r.crackDuration <= 0 {
+			r.crackDuration = 0
+			r.State = "broken"
+		}
+	}
+}
+
+func (r *Rock) Crack(duration float32) {
+	r.State = "cracking"
+	r.crackDuration = duration
+}
+
+func (r *Rock) Draw(tm *Tilemap, offset rl.Vector2) {
+	if r.State == "broken" {
+		return
+	}
+	if r.State == "cracking" {
+		offset.X += float32(math.Sin(float64(r.crackDuration))) * 2
+	}
+	for _, t := range r.Tiles {
+		tm.DrawTile(t, offset)
+	}
+}
+
 type Tilemap struct {
 	TileLayers       []map[rl.Vector2]Tile
 	Objects          []Tile
 	Obstacles        map[rl.Vector2]bool
 	Trees            []Tree
-	Beds             map[rl.Vector2]bool
+	Rocks            []Rock
+	treeAssets       map[string]strip.StripImg
+	treeHunkImg      rl.Texture2D
 	tilesetAsset     rl.Texture2D
 	Tilesize         int
 	tilesetCols      int
 	tilesetRows      int
 	Roofs            []Tile
-	FarmTiles        map[rl.Vector2]FarmTile
 	CropAssets       map[string]strip.StripImg
 	SeedShop         MerchantTile
+	Blacksmith       MerchantTile
 	TileScale        int
 	ChimneySmokeList []anim.AnimatedTile
 }
@@ -172,9 +283,12 @@ func (tm *Tilemap) DrawTerrain(offset rl.Vector2, screenSize rl.Vector2) {
 	// }
 }
 
-func (tm *Tilemap) DrawFarmTiles(offset rl.Vector2) {
-	for _, ft := range tm.FarmTiles {
-		cellpos := ft.Pos
+// grass tufts of the tileset, picked by cell so neighbouring weeds differ
+var weedTiles = []int{155, 156, 157, 158}
+
+func (tm *Tilemap) DrawFarmTiles(offset rl.Vector2, farm *sim.Farm) {
+	for _, ft := range farm.Tiles {
+		cellpos := simCellPos(ft.Cell)
 		tilesize := float32(tm.Tilesize)
 		viewpos := rl.Vector2Subtract(
 			rl.NewVector2(
@@ -188,8 +302,17 @@ func (tm *Tilemap) DrawFarmTiles(offset rl.Vector2) {
 				rl.DrawRectangleV(viewpos, rl.NewVector2(tilesize, tilesize), rl.NewColor(139, 69, 19, 60))
 			}
 		}
+		if ft.State == "weeds" {
+			rl.DrawTexturePro(
+				tm.tilesetAsset,
+				tm.GetSrcRect(weedTiles[(ft.Cell.X+ft.Cell.Y)%len(weedTiles)]),
+				tm.GetDestRect(cellpos, offset),
+				rl.NewVector2(0, 0),
+				0,
+				rl.White,
+			)
+		}
 		if ft.State == "digged" {
-			cellpos := ft.Pos
 			tilesize := float32(tm.Tilesize)
 			viewpos := rl.Vector2Subtract(
 				rl.NewVector2(
@@ -211,7 +334,8 @@ func (tm *Tilemap) DrawFarmTiles(offset rl.Vector2) {
 			}
 		} else if ca, ok := tm.CropAssets[ft.State]; ok {
 			soil := tm.CropAssets["soil"]
-			age := min(ft.CropAge, ca.StripCount-1)
+			c, _ := farm.Crop(ft.State)
+			age := min(c.Stage(ft.CropAge), ca.StripCount-1, soil.StripCount-1)
 			rl.DrawTexturePro(
 				soil.Img,
 				soil.SrcRects[age],
@@ -223,9 +347,15 @@ func (tm *Tilemap) DrawFarmTiles(offset rl.Vector2) {
 			if ft.IsWet {
 				rl.DrawRectangleV(viewpos, rl.NewVector2(tilesize, tilesize), rl.NewColor(139, 69, 19, 60))
 			}
+			// withered crops of every kind show the same dead plant
+			src := ca.SrcRects[age]
+			if ft.Withered {
+				ca = tm.CropAssets["withered"]
+				src = ca.SrcRects[0]
+			}
 			rl.DrawTexturePro(
 				ca.Img,
-				ca.SrcRects[age],
+				src,
 				rl.NewRectangle(viewpos.X, viewpos.Y, tilesize, tilesize),
 				rl.NewVector2(0, 0),
 				0,
@@ -294,28 +424,76 @@ func (tm *Tilemap) ExtractObjects(objs []string) []Tile {
 func (tm *Tilemap) GetObstaclesAround(pos rl.Vector2) []rl.Rectangle {
 	treeRects := []rl.Rectangle{}
 	for _, t := range tm.Trees {
-		treeRects = append(treeRects, t.Hitbox)
+		if t.Blocks() {
+			treeRects = append(treeRects, t.Hitbox)
+		}
+	}
+	for _, r := range tm.Rocks {
+		if r.State != "broken" {
+			treeRects = append(treeRects, r.Hitbox)
+		}
 	}
 	return append(world.GetTileRectsAround(tm.Obstacles, pos, float32(tm.Tilesize)), treeRects...)
 }
 
-func (tm *Tilemap) GetFarmRectsAround(pos rl.Vector2) []rl.Rectangle {
-	return world.GetTileRectsAround(tm.FarmTiles, pos, float32(tm.Tilesize))
+func (tm *Tilemap) TreeCell(t Tree) rl.Vector2 {
+	return world.GetCellPos(t.Pos, float64(tm.Tilesize))
+}
+
+// NewTree returns the visual of a tree of kind rooted on cellpos
+func (tm *Tilemap) NewTree(kind int, cellpos rl.Vector2) Tree {
+	return NewTree(tm.treeAssets[treeAssetNames[kind]], tm.treeHunkImg, cellpos, float32(tm.Tilesize), float32(tm.TileScale))
 }
 
-func (tm *Tilemap) AddFarmHole(pos rl.Vector2) {
-	cellpos := world.GetCellPos(pos, float64(tm.Tilesize))
-	if t, ok := tm.FarmTiles[cellpos]; ok {
-		t.State = "digged"
-		tm.FarmTiles[cellpos] = t
+// SyncTrees makes the tree visuals follow the simulated trees and adds the visuals of planted trees.
+// Felled trees shake for shakeDuration before turning into a stump and stumps jitter as long before
+// they are gone, or both happen right away when it is 0. A hit that leaves the tree standing wobbles it as long.
+func (tm *Tilemap) SyncTrees(trees []sim.Tree, shakeDuration float32) {
+	for _, st := range trees {
+		cellpos := simCellPos(st.Cell)
+		i := slices.IndexFunc(tm.Trees, func(t Tree) bool {
+			return tm.TreeCell(t) == cellpos
+		})
+		if i == -1 {
+			tm.Trees = append(tm.Trees, tm.NewTree(st.Kind, cellpos))
+			i = len(tm.Trees) - 1
+		}
+		t := &tm.Trees[i]
+		hit := st.Health < t.Health && shakeDuration > 0
+		switch {
+		case st.State == "dead" && t.State == "idle" && shakeDuration > 0:
+			t.Shake(shakeDuration)
+		case st.State == "cleared" && t.State == "dead" && shakeDuration > 0:
+			t.Uproot(shakeDuration)
+		case t.State == "shaking" || t.State == "uprooting":
+		case st.State == t.State && hit:
+			t.Wobble(shakeDuration)
+		default:
+			t.State = st.State
+		}
+		t.Health = st.Health
 	}
 }
 
-func (tm *Tilemap) AddWetTile(pos rl.Vector2) {
-	cellpos := world.GetCellPos(pos, float64(tm.Tilesize))
-	if t, ok := tm.FarmTiles[cellpos]; ok {
-		t.IsWet = true
-		tm.FarmTiles[cellpos] = t
+// SyncRocks makes the rock visuals follow the simulated rocks. Mined rocks crack for crackDuration
+// before they disappear, or disappear right away when it is 0.
+func (tm *Tilemap) SyncRocks(rocks []sim.Rock, crackDuration float32) {
+	for i, r := range tm.Rocks {
+		idx := slices.IndexFunc(rocks, func(sr sim.Rock) bool {
+			return simCellPos(sr.Cell) == r.Cell
+		})
+		if idx == -1 {
+			continue
+		}
+		switch {
+		case rocks[idx].State == "idle":
+			tm.Rocks[i].State = "idle"
+		case r.State != "idle":
+		case crackDuration > 0:
+			tm.Rocks[i].Crack(crackDuration)
+		default:
+			tm.Rocks[i].State = "broken"
+		}
 	}
 }
 
@@ -333,12 +511,30 @@ func (tm *Tilemap) GetTiles(tiles []Tile, types []string) []Tile {
 	return res
 }
 
+// TreeSprite depth sorts the tree at index i, it looks the tree up on every draw as tm.Trees grows
+func TreeSprite(tm *Tilemap, i int) render.Sprite {
+	return render.Sprite{
+		Draw: func(offset rl.Vector2, drawRoof bool) {
+			tm.Trees[i].Draw(offset)
+		},
+		Center: func() rl.Vector2 {
+			return tm.Trees[i].Center
+		},
+	}
+}
+
 func GetCollidedTreeIdx(trees []Tree, hitpoint rl.Vector2) int {
 	return slices.IndexFunc(trees, func(t Tree) bool {
 		return rl.CheckCollisionPointRec(hitpoint, t.Hitbox)
 	})
 }
 
+func GetCollidedRockIdx(rocks []Rock, hitpoint rl.Vector2) int {
+	return slices.IndexFunc(rocks, func(r Rock) bool {
+		return rl.CheckCollisionPointRec(hitpoint, r.Hitbox)
+	})
+}
+
 func LoadImgWithScale(imgPath string, scale int32) rl.Texture2D {
 	var img = rl.LoadImage(imgPath)
 	defer rl.UnloadImage(img)
@@ -363,13 +559,14 @@ func LoadTilemap(
 	tm.tilesetCols = int(tm.tilesetAsset.Width) / tilesize
 	tm.tilesetRows = int(tm.tilesetAsset.Height) / tilesize
 	tm.Trees = []Tree{}
+	tm.treeAssets = treeAssets
+	tm.treeHunkImg = treeHunkImg
+	tm.Rocks = []Rock{}
 	tm.TileLayers = []map[rl.Vector2]Tile{}
 	tm.Obstacles = map[rl.Vector2]bool{}
 	tm.Objects = []Tile{}
-	tm.FarmTiles = map[rl.Vector2]FarmTile{}
 	tm.TileScale = scale
 	tm.CropAssets = cropAssets
-	tm.Beds = map[rl.Vector2]bool{}
 	tm.ChimneySmokeList = []anim.AnimatedTile{}
 
 	var width = tmd.Width
@@ -400,32 +597,11 @@ func LoadTilemap(
 			}
 			cellpos := rl.NewVector2(float32(i%width), float32(i/width))
 			if layer.Name == "seed_shop" && id > 0 {
-				baseImg := humanAnimStyles["IDLE"].Base
-				assetSize := rl.NewVector2(float32(baseImg.Width)/float32(humanAnimStyles["IDLE"].StripCount), float32(baseImg.Height)/2)
-				size := rl.NewVector2(
-					float32(assetSize.X)*float32(scale),
-					float32(assetSize.Y)*float32(scale),
-				)
-				offsetSize := rl.NewVector2(
-					size.X*0.5-float32(tilesize)*0.5,
-					size.Y*0.5-float32(tilesize)*0.5,
-				)
-				tm.SeedShop = MerchantTile{
-					Rect:    rl.NewRectangle(cellpos.X*float32(tilesize), cellpos.Y*float32(tilesize), float32(tilesize), float32(tilesize)),
-					imgRect: rl.NewRectangle(cellpos.X*float32(tilesize)-offsetSize.X, cellpos.Y*float32(tilesize)-offsetSize.Y, size.X, size.Y),
-					BaseAnim: anim.NewStripAnimation(
-						baseImg,
-						assetSize,
-						12,
-						float32(humanAnimStyles["IDLE"].StripCount),
-					),
-					StyleAnim: anim.NewStripAnimation(
-						humanAnimStyles["IDLE"].Variants["bowlhair"],
-						assetSize,
-						12,
-						float32(humanAnimStyles["IDLE"].StripCount),
-					),
-				}
+				tm.SeedShop = NewMerchantTile(humanAnimStyles["IDLE"], "bowlhair", cellpos, tilesize, scale)
+				continue
+			}
+			if layer.Name == "blacksmith" && id > 0 {
+				tm.Blacksmith = NewMerchantTile(humanAnimStyles["IDLE"], "spikeyhair", cellpos, tilesize, scale)
 				continue
 			}
 			if layer.Name == "obstacles" && id > 0 {
@@ -433,23 +609,25 @@ func LoadTilemap(
 				continue
 			}
 			if layer.Name == "bed" && id > 0 {
-				tm.Beds[cellpos] = true
 				continue
 			}
 			if layer.Name == "farm_tile" && id > 0 {
-				tm.FarmTiles[cellpos] = FarmTile{
-					Pos:     cellpos,
-					State:   "empty",
-					CropAge: 0,
-				}
 				continue
 			}
-			if layer.Name == "tree_real" && id > 0 {
-				if id == 4102 {
-					tm.Trees = append(tm.Trees, NewTree(treeAssets["tree_01"], treeHunkImg, cellpos, float32(tm.Tilesize), float32(tm.TileScale), 5))
-				} else if id == 4103 {
-					tm.Trees = append(tm.Trees, NewTree(treeAssets["tree_02"], treeHunkImg, cellpos, float32(tm.Tilesize), float32(tm.TileScale), 2))
+			if offset, ok := rockTileOffset[int(id)]; ok && layer.Name == "rocks" {
+				corner := rl.Vector2Subtract(cellpos, offset)
+				idx := slices.IndexFunc(tm.Rocks, func(r Rock) bool {
+					return r.Cell == corner
+				})
+				if idx == -1 {
+					tm.Rocks = append(tm.Rocks, NewRock(corner, float32(tilesize)))
+					idx = len(tm.Rocks) - 1
 				}
+				tm.Rocks[idx].Tiles = append(tm.Rocks[idx].Tiles, Tile{Type: layer.Name, Variant: int(id - 1), Pos: cellpos})
+				continue
+			}
+			if _, ok := treeAssetNames[int(id)]; ok && layer.Name == "tree_real" {
+				tm.Trees = append(tm.Trees, tm.NewTree(int(id), cellpos))
 			}
 
 			if z == -1 {
@@ -467,6 +645,12 @@ func LoadTilemap(
 	sort.SliceStable(tm.Objects, func(i, j int) bool {
 		return tm.Objects[i].Center(float32(tm.Tilesize)).Y < tm.Objects[j].Center(float32(tm.Tilesize)).Y
 	})
+	// rocks block the way with their hitbox until they are mined
+	for _, r := range tm.Rocks {
+		for _, t := range r.Tiles {
+			delete(tm.Obstacles, t.Pos)
+		}
+	}
 
 	houseRoofs := []string{"house_roof_float", "house_roof_float_front", "house_roof", "house_roof_front"}
 	tm.Roofs = tm.ExtractObjects(houseRoofs)
@@ -487,6 +671,36 @@ type MerchantTile struct {
 	Rect      rl.Rectangle
 }
 
+// NewMerchantTile stands a merchant with a hair style on cellpos
+func NewMerchantTile(idle anim.AnimStyle, style string, cellpos rl.Vector2, tilesize int, scale int) MerchantTile {
+	baseImg := idle.Base
+	assetSize := rl.NewVector2(float32(baseImg.Width)/float32(idle.StripCount), float32(baseImg.Height)/2)
+	size := rl.NewVector2(
+		float32(assetSize.X)*float32(scale),
+		float32(assetSize.Y)*float32(scale),
+	)
+	offsetSize := rl.NewVector2(
+		size.X*0.5-float32(tilesize)*0.5,
+		size.Y*0.5-float32(tilesize)*0.5,
+	)
+	return MerchantTile{
+		Rect:    rl.NewRectangle(cellpos.X*float32(tilesize), cellpos.Y*float32(tilesize), float32(tilesize), float32(tilesize)),
+		imgRect: rl.NewRectangle(cellpos.X*float32(tilesize)-offsetSize.X, cellpos.Y*float32(tilesize)-offsetSize.Y, size.X, size.Y),
+		BaseAnim: anim.NewStripAnimation(
+			baseImg,
+			assetSize,
+			12,
+			float32(idle.StripCount),
+		),
+		StyleAnim: anim.NewStripAnimation(
+			idle.Variants[style],
+			assetSize,
+			12,
+			float32(idle.StripCount),
+		),
+	}
+}
+
 func (t *MerchantTile) Update(dt float32) {
 	t.BaseAnim.Update(dt)
 	t.StyleAnim.Update(dt)
@@ -498,11 +712,149 @@ func (t *MerchantTile) Draw(offset rl.Vector2) {
 	rl.DrawTexturePro(t.StyleAnim.Image, t.StyleAnim.SrcRect(false), destRect, rl.NewVector2(0, 0), 0, rl.White)
 }
 
-func LoadToolUIAsset() map[string]rl.Texture2D {
-	res := map[string]rl.Texture2D{}
-	res["axe"] = rl.LoadTexture("./resources/UI/axe.png")
-	res["shovel"] = rl.LoadTexture("./resources/UI/shovel.png")
-	res["water"] = rl.LoadTexture("./resources/UI/water.png")
+func GameData(farmName string, playtime float64, player *entity.Player, game *sim.Game) save.Data {
+	data := game.Save()
+	data.FarmName = farmName
+	data.Playtime = playtime
+	data.PlayerPos = save.Position{X: player.Pos.X, Y: player.Pos.Y}
+	return data
+}
+
+func SaveGame(path string, farmName string, playtime float64, player *entity.Player, game *sim.Game) error {
+	return save.Write(path, GameData(farmName, playtime, player, game))
+}
+
+func RestoreGame(data save.Data, player *entity.Player, tm *Tilemap, game *sim.Game) {
+	game.Load(data)
+	tm.SyncTrees(game.Farm.Trees, 0)
+	tm.SyncRocks(game.Farm.Rocks, 0)
+	player.Pos = rl.NewVector2(data.PlayerPos.X, data.PlayerPos.Y)
+	player.PrevPos = player.Pos
+}
+
+// LoadGame restores the state written by SaveGame and returns the raw save data
+func LoadGame(path string, player *entity.Player, tm *Tilemap, game *sim.Game) (save.Data, error) {
+	data, err := save.Read(path)
+	if err != nil {
+		return save.Data{}, err
+	}
+	RestoreGame(data, player, tm, game)
+	return data, nil
+}
+
+// the simulation always advances by fixedDt seconds, whatever the frame rate
+const fixedDt float32 = 1.0 / 60
+
+// longest frame time simulated at once
+const maxFrameTime float32 = 0.25
+
+// overlay colors of the day, DaylightOverlay blends between the two around the clock
+var daylight = []struct {
+	Clock calendar.Clock
+	Color rl.Color
+}{
+	{6 * 60, rl.NewColor(84, 88, 131, 80)},
+	{8 * 60, rl.NewColor(255, 255, 255, 0)},
+	{14 * 60, rl.NewColor(247, 228, 160, 30)},
+	{18 * 60, rl.NewColor(255, 151, 89, 80)},
+	{20 * 60, rl.NewColor(70, 63, 103, 100)},
+	{22 * 60, rl.NewColor(4, 26, 54, 150)},
+	{26 * 60, rl.NewColor(4, 26, 54, 150)},
+}
+
+func DaylightOverlay(clock calendar.Clock) rl.Color {
+	for i := 1; i < len(daylight); i++ {
+		from, to := daylight[i-1], daylight[i]
+		if clock < to.Clock {
+			t := float32(max(clock-from.Clock, 0) / (to.Clock - from.Clock))
+			return rl.NewColor(
+				uint8(float32(from.Color.R)+(float32(to.Color.R)-float32(from.Color.R))*t),
+				uint8(float32(from.Color.G)+(float32(to.Color.G)-float32(from.Color.G))*t),
+				uint8(float32(from.Color.B)+(float32(to.Color.B)-float32(from.Color.B))*t),
+				uint8(float32(from.Color.A)+(float32(to.Color.A)-float32(from.Color.A))*t),
+			)
+		}
+	}
+	return daylight[len(daylight)-1].Color
+}
+
+type TitleChoice struct {
+	Slot save.SlotInfo
+	// set when the player picked a LAN game instead of a local farm
+	JoinAddr string
+}
+
+// PickSlot runs the title screen until the player creates or loads a farm, or joins a LAN game.
+// browser may be nil when discovery is unavailable. It returns false if the window was closed.
+func PickSlot(slots save.Slots, browser *lan.Browser, screenSize rl.Vector2) (TitleChoice, bool) {
+	infos, err := slots.List()
+	if err != nil {
+		log.Printf("could not list save slots: %v", err)
+	}
+	picker := ui.NewSlotPicker(screenSize, infos)
+	for !rl.WindowShouldClose() {
+		if browser != nil {
+			picker.SetHosts(browser.Hosts())
+		}
+		action := picker.Update(rl.GetFrameTime(), rl.GetMousePosition())
+		switch action.Kind {
+		case ui.SlotNew:
+			slot, err := slots.Create(action.FarmName)
+			if err == nil {
+				return TitleChoice{Slot: slot}, true
+			}
+			log.Printf("could not create slot: %v", err)
+		case ui.SlotLoad:
+			slot, err := slots.Info(action.SlotID)
+			if err == nil {
+				return TitleChoice{Slot: slot}, true
+			}
+			log.Printf("could not load slot %s: %v", action.SlotID, err)
+		case ui.SlotJoin:
+			return TitleChoice{JoinAddr: action.Addr}, true
+		case ui.SlotDuplicate:
+			if _, err := slots.Duplicate(action.SlotID); err != nil {
+				log.Printf("could not duplicate slot %s: %v", action.SlotID, err)
+			}
+		case ui.SlotDelete:
+			if err := slots.Delete(action.SlotID); err != nil {
+				log.Printf("could not delete slot %s: %v", action.SlotID, err)
+			}
+		}
+		if action.Kind == ui.SlotDuplicate || action.Kind == ui.SlotDelete {
+			if infos, err := slots.List(); err == nil {
+				picker.SetSlots(infos)
+			}
+		}
+
+		rl.BeginDrawing()
+		rl.ClearBackground(rl.NewColor(84, 88, 131, 255))
+		title := "Farm sim"
+		titleWidth := rl.MeasureText(title, 48)
+		rl.DrawText(title, int32(screenSize.X*0.5)-titleWidth/2, 20, 48, rl.White)
+		picker.Draw()
+		rl.EndDrawing()
+	}
+	return TitleChoice{}, false
+}
+
+// LoadCropAssets loads the strip of every crop in defs along with the soil and wood strips
+func LoadCropAssets(dirpath string, defs []crop.Definition) (map[string]strip.StripImg, error) {
+	files, err := crop.FindStrips(dirpath, []string{"soil", "wood", "rock", "fish", "withered"})
+	if err != nil {
+		return map[string]strip.StripImg{}, err
+	}
+	for name, f := range crop.Strips(dirpath, defs) {
+		files[name] = f
+	}
+	return LoadStripFiles(files), nil
+}
+
+func LoadStripFiles(files map[string]crop.StripFile) map[string]strip.StripImg {
+	res := map[string]strip.StripImg{}
+	for name, f := range files {
+		res[name] = strip.NewStripImg(rl.LoadTexture(f.Path), int32(f.StripCount))
+	}
 	return res
 }
 
@@ -513,6 +865,26 @@ func UnloadTextureMap[K comparable](assets map[K]rl.Texture2D) {
 }
 
 func main() {
+	hostAddr := flag.String("host", "", "host a LAN game on this address, e.g. :7777")
+	joinAddr := flag.String("join", "", "join the LAN game at this address, the farm is not saved locally")
+	playerName := flag.String("name", "Farmer", "player name shown to other LAN players")
+	playerStyle := flag.String("style", "shorthair", "hair style: bowlhair, curlyhair, longhair, mophair, shorthair or spikeyhair")
+	announceAddr := flag.String("announce", lan.DefaultBroadcastAddr, "where a LAN host broadcasts its farm, use 127.0.0.1:7778 to test on one machine")
+	recordPath := flag.String("record", "", "record the inputs of a solo game to this file")
+	replayPath := flag.String("replay", "", "play back a recording, the save file is left untouched")
+	soilDecay := flag.Int("soil-decay", sim.DefaultSoilDecayDays, "days digged soil without a crop takes to turn back to grass, 0 to disable")
+	flag.Parse()
+
+	var playback *replay.Replay[Input]
+	if *replayPath != "" {
+		r, err := replay.Open[Input](*replayPath, InputVersion)
+		if err != nil {
+			log.Printf("could not open replay %s: %v", *replayPath, err)
+			return
+		}
+		playback = r
+	}
+
 	const WIDTH = 1280
 	const HEIGHT = 720
 	rl.InitWindow(WIDTH, HEIGHT, "Farm sim")
@@ -520,14 +892,27 @@ func main() {
 	rl.SetTargetFPS(60)
 	originalTilesize := 16
 
-	// tileset id
-	var crops = []string{"carrot", "cauliflower", "pumpkin", "sunflower", "radish", "parsnip", "potato", "cabbage", "beetroot", "wheat", "kale"}
-	cropAssets, err := crop.LoadCropAssets("./resources/elements/Crops", append(crops, "soil", "wood"))
+	catalog, err := items.LoadCatalog("./resources/data/items.json")
+	if err != nil {
+		log.Printf("could not read items: %v", err)
+		return
+	}
+	crops, err := crop.Load("./resources/data/crops.json", catalog)
+	if err != nil {
+		log.Printf("could not read crops: %v", err)
+		return
+	}
+	fishes, err := sim.LoadFishes("./resources/data/fish.json", catalog)
+	if err != nil {
+		log.Printf("could not read fish: %v", err)
+		return
+	}
+	cropAssets, err := LoadCropAssets("./resources/elements/crops", crops)
 	if err != nil {
 		return
 	}
 	defer strip.UnloadMapStripImg(cropAssets)
-	woodDropSfx := sfx.NewItemDrop(cropAssets["wood"].Img, 50)
+	weatherSfx := sfx.NewWeather(rl.NewVector2(WIDTH, HEIGHT))
 
 	uiAssets := map[string]rl.Texture2D{
 		"selectbox_bl": rl.LoadTexture("./resources/UI/selectbox_bl.png"),
@@ -536,6 +921,18 @@ func main() {
 		"selectbox_tr": rl.LoadTexture("./resources/UI/selectbox_tr.png"),
 		"arrow_left":   rl.LoadTexture("./resources/UI/arrow_left.png"),
 		"arrow_right":  rl.LoadTexture("./resources/UI/arrow_right.png"),
+		// a fish bites
+		"expression_alerted": rl.LoadTexture("./resources/UI/expression_alerted.png"),
+	}
+	for i := 0; i <= energyBarFrames; i++ {
+		for _, bar := range []string{"greenbar", "redbar"} {
+			name := fmt.Sprintf("%s_%02d", bar, i)
+			uiAssets[name] = rl.LoadTexture(fmt.Sprintf("./resources/UI/%s.png", name))
+		}
+	}
+	for i := 0; i <= waterBarFrames; i++ {
+		name := fmt.Sprintf("bluebar_%02d", i)
+		uiAssets[name] = rl.LoadTexture(fmt.Sprintf("./resources/UI/%s.png", name))
 	}
 	defer UnloadTextureMap(uiAssets)
 	treeAssets := map[string]strip.StripImg{
@@ -546,7 +943,7 @@ func main() {
 	treeHunkImg := rl.LoadTexture("./resources/elements/Plants/tree_hunk.png")
 	defer rl.UnloadTexture(treeHunkImg)
 
-	supportedStyles := []string{"IDLE", "WALKING", "WATERING", "DIG", "AXE"}
+	supportedStyles := []string{"IDLE", "WALKING", "WATERING", "DIG", "AXE", "MINING", "CASTING", "WAITING", "REELING", "CAUGHT"}
 	humanAnimStyles := anim.NewAnimStyles("./resources/characters/Human", supportedStyles)
 
 	chimneySmoke := anim.LoadStripAnimation("./resources/elements/VFX/Chimney Smoke/chimneysmoke_03_strip30.png", 10)
@@ -556,21 +953,8 @@ func main() {
 	tm := LoadTilemap(&tmd, cropAssets, treeAssets, treeHunkImg, humanAnimStyles, chimneySmoke, 48)
 	defer tm.Unload()
 
-	const cropTilesetStartId = 691
-	getFullCropTileId := func(cropName string) int {
-		idx := slices.Index(crops, cropName)
-		return cropTilesetStartId + idx
-	}
-
 	defer anim.UnloadAnimStyles(humanAnimStyles)
 
-	toolsUIAsset := LoadToolUIAsset()
-	defer UnloadTextureMap(toolsUIAsset)
-	tools := []string{}
-	for t := range toolsUIAsset {
-		tools = append(tools, t)
-	}
-
 	playerTile := tm.ExtractObjectOne("player")
 	if playerTile == nil {
 		return
@@ -581,8 +965,7 @@ func main() {
 		tm.Tilesize,
 		tm.Tilesize/originalTilesize,
 		humanAnimStyles,
-		tools,
-		"shorthair",
+		*playerStyle,
 	)
 
 	depthRenderer := render.NewDepthRenderer(20)
@@ -603,20 +986,26 @@ func main() {
 		}
 	}
 	for i := range tm.Trees {
+		depthRenderer.Sprites = append(depthRenderer.Sprites, TreeSprite(&tm, i))
+	}
+	// trees planted after the map was loaded are registered as they appear
+	treeSprites := len(tm.Trees)
+	for i := range tm.Rocks {
 		depthRenderer.Sprites = append(depthRenderer.Sprites, render.Sprite{
 			Draw: func(offset rl.Vector2, drawRoof bool) {
-				tm.Trees[i].Draw(offset)
+				tm.Rocks[i].Draw(&tm, offset)
 			},
 			Center: func() rl.Vector2 {
-				return tm.Trees[i].Center
+				return tm.Rocks[i].Center
 			},
 		})
-
 	}
 
+	// how far the drawn player is from its simulated position
+	playerShift := rl.NewVector2(0, 0)
 	depthRenderer.Sprites = append(depthRenderer.Sprites, render.Sprite{
 		Draw: func(offset rl.Vector2, drawRoof bool) {
-			player.Draw(offset)
+			player.Draw(rl.Vector2Subtract(offset, playerShift))
 		},
 		Center: func() rl.Vector2 {
 			return rl.NewVector2(player.Center().X, player.Pos.Y+float32(tm.Tilesize)*0.5)
@@ -630,210 +1019,340 @@ func main() {
 			return rl.NewVector2(tm.SeedShop.imgRect.X+tm.SeedShop.imgRect.Width*0.5, tm.SeedShop.imgRect.Y+tm.SeedShop.imgRect.Height*0.5)
 		},
 	})
+	depthRenderer.Sprites = append(depthRenderer.Sprites, render.Sprite{
+		Draw: func(offset rl.Vector2, drawRoof bool) {
+			tm.Blacksmith.Draw(offset)
+		},
+		Center: func() rl.Vector2 {
+			return rl.NewVector2(tm.Blacksmith.imgRect.X+tm.Blacksmith.imgRect.Width*0.5, tm.Blacksmith.imgRect.Y+tm.Blacksmith.imgRect.Height*0.5)
+		},
+	})
+
+	game := sim.NewGame(&tmd, crops, fishes, catalog)
+	game.Farm.SoilDecayDays = *soilDecay
 
-	allItems := items.LoadItems(cropAssets)
-	defer items.UnloadItems(allItems)
+	itemImages := ui.LoadItemImages(catalog.Items(), cropAssets)
+	// tools show their icon of the UI folder
+	for _, item := range catalog.Items() {
+		if item.Type == "tool" {
+			itemImages[item.ID] = rl.LoadTexture(fmt.Sprintf("./resources/UI/%s.png", item.Sprite))
+		}
+		// saplings show their tree at half size
+		if item.Type == "sapling" {
+			img := rl.LoadImage(fmt.Sprintf("./resources/elements/Plants/%s.png", item.Sprite))
+			rl.ImageResizeNN(img, img.Width/2, img.Height/2)
+			itemImages[item.ID] = rl.LoadTextureFromImage(img)
+			rl.UnloadImage(img)
+		}
+	}
+	defer UnloadTextureMap(itemImages)
 
-	playerInventory := items.NewInventory(allItems)
-	inventoryUI := items.NewInventoryUI(WIDTH, HEIGHT, float32(tm.Tilesize))
+	inventoryUI := ui.NewInventoryUI(WIDTH, HEIGHT, float32(tm.Tilesize), itemImages)
+	hotbarUI := ui.NewHotbarUI(WIDTH, HEIGHT, float32(tm.Tilesize), itemImages)
 	showInventory := false
-	seedShop := items.NewSeedShop("Seed merchant", allItems)
-	seedShopUI := items.NewShopUI(rl.NewVector2(WIDTH, HEIGHT), float32(tm.Tilesize), uiAssets)
-	showShop := false
-
-	currentSeed := playerInventory.AvailableSeeds()[0]
-	seedUiPos := rl.NewVector2(
-		float32(tm.Tilesize),
-		HEIGHT-80,
-	)
+	seedShopUI := ui.NewShopUI(rl.NewVector2(WIDTH, HEIGHT), float32(tm.Tilesize), uiAssets, itemImages)
+	blacksmithUI := ui.NewShopUI(rl.NewVector2(WIDTH, HEIGHT), float32(tm.Tilesize), uiAssets, itemImages)
+	// shop the player trades with and its window, nil while no shop is open
+	var openShop *items.Shop
+	shopUI := &seedShopUI
+
+	session := NewSession(func(id int, style string) entity.RemotePlayer {
+		return entity.NewRemotePlayer(id, tm.Tilesize, tm.Tilesize/originalTilesize, humanAnimStyles, style)
+	}, &depthRenderer, *playerStyle)
+	defer session.Close()
+
+	var playtime float64 = 0
+	farmName := ""
+	saveFile := ""
+	join := *joinAddr
+	// the items on the ground come back once the drops are set up
+	var ground []save.Drop
+	if join == "" && playback == nil {
+		browser, err := lan.Browse(fmt.Sprintf(":%d", lan.DefaultDiscoveryPort), 5*time.Second)
+		if err != nil {
+			log.Printf("LAN discovery unavailable: %v", err)
+		}
+		choice, ok := PickSlot(save.NewSlots("./saves"), browser, rl.NewVector2(WIDTH, HEIGHT))
+		if browser != nil {
+			browser.Close()
+		}
+		if !ok {
+			return
+		}
+		join = choice.JoinAddr
+		farmName = choice.Slot.FarmName
+		saveFile = choice.Slot.Path
+	}
+	if playback != nil {
+		// start from the recorded state, nothing is saved while replaying
+		RestoreGame(playback.Header.Start, &player, &tm, game)
+		playtime = playback.Header.Start.Playtime
+		farmName = playback.Header.Start.FarmName
+		ground = playback.Header.Start.Drops
+		game.Farm.SoilDecayDays = playback.Header.SoilDecayDays
+		game.Farm.WeedChance = playback.Header.WeedChance
+		log.Printf("replaying %d steps of %s", playback.Len(), farmName)
+	} else if join != "" {
+		client, err := lan.Dial(join, lan.Hello{Name: *playerName, Style: *playerStyle})
+		if err != nil {
+			log.Printf("could not join %s: %v", join, err)
+			return
+		}
+		session.Client = client
+	} else {
+		if data, err := LoadGame(saveFile, &player, &tm, game); err == nil {
+			playtime = data.Playtime
+			farmName = data.FarmName
+			ground = data.Drops
+		} else if errors.Is(err, fs.ErrNotExist) {
+			// new farm, write it right away so it shows up in the slot list
+			if err := SaveGame(saveFile, farmName, playtime, &player, game); err != nil {
+				log.Printf("could not create %s: %v", saveFile, err)
+			}
+		} else {
+			log.Printf("could not load %s: %v", saveFile, err)
+		}
+		if *hostAddr != "" {
+			host, err := lan.Listen(*hostAddr)
+			if err != nil {
+				log.Printf("could not host on %s: %v", *hostAddr, err)
+				return
+			}
+			log.Printf("hosting %s on %s", farmName, host.Addr())
+			session.Host = host
+			session.FarmName = farmName
+			announcer, err := lan.NewAnnouncer(*announceAddr, 2*time.Second, session.Announcement(game.Day))
+			if err != nil {
+				log.Printf("could not announce on %s: %v", *announceAddr, err)
+			} else {
+				session.Announcer = announcer
+			}
+		}
+	}
+	// clients play on the host's farm, only the host keeps a save file
+	drops := entity.NewDrops(float32(tm.Tilesize))
+	// items gained from a tree or rock wait until it stops shaking to drop
+	pendingGains := []lan.InventoryChange{}
+	session.Gain = func(change lan.InventoryChange) {
+		// a catch is held up by the player and does not fall in the water
+		if item, ok := catalog.Item(change.Item); ok && item.Type == "fish" {
+			game.Inventory.Add(change.Item, items.Quality(change.Quality), change.Delta)
+			return
+		}
+		pendingGains = append(pendingGains, change)
+	}
+	for _, d := range ground {
+		drops.Place(d.ID, items.Quality(d.Quality), itemImages[d.ID], rl.NewVector2(d.Pos.X, d.Pos.Y))
+	}
+	gameData := func() save.Data {
+		data := GameData(farmName, playtime, &player, game)
+		data.Drops = []save.Drop{}
+		for _, d := range drops.Items {
+			data.Drops = append(data.Drops, save.Drop{ID: d.Item, Quality: int(d.Quality), Pos: save.Position{X: d.Pos.X, Y: d.Pos.Y}})
+		}
+		// gains still waiting for a tree or rock to stop shaking lie next to it
+		for _, change := range pendingGains {
+			pos, _ := dropPos(&tm, change.Cell)
+			for range change.Delta {
+				data.Drops = append(data.Drops, save.Drop{ID: change.Item, Quality: change.Quality, Pos: save.Position{X: pos.X, Y: pos.Y}})
+			}
+		}
+		return data
+	}
+	saveGame := func() {
+		if saveFile == "" {
+			return
+		}
+		if err := save.Write(saveFile, gameData()); err != nil {
+			log.Printf("save failed: %v", err)
+		}
+	}
+
+	// every random number of the game comes from rng so a replay rolls the same ones
+	seed := rand.Uint64()
+	if playback != nil {
+		seed = playback.Header.Seed
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	game.Farm.Rand = rng
+
+	var recorder *replay.Recorder[Input]
+	if *recordPath != "" {
+		if session.Host != nil || session.Client != nil || playback != nil {
+			log.Printf("recording only works in solo games")
+		} else {
+			header := replay.Header{
+				Frames:        InputVersion,
+				Seed:          seed,
+				Start:         gameData(),
+				SoilDecayDays: game.Farm.SoilDecayDays,
+				WeedChance:    game.Farm.WeedChance,
+			}
+			r, err := replay.Create[Input](*recordPath, header)
+			if err != nil {
+				log.Printf("could not record to %s: %v", *recordPath, err)
+			} else {
+				recorder = r
+				defer func() {
+					if recorder == nil {
+						return
+					}
+					if err := recorder.Close(); err != nil {
+						log.Printf("could not finish recording %s: %v", *recordPath, err)
+					}
+				}()
+			}
+		}
+	}
+
+	// cells the shovel digs once its swing hits the ground
+	var digCells []sim.Cell
+	fishing := Fishing{Act: func(use lan.ToolUse) { session.Act(use, game) }}
 
 	var camScroll = rl.NewVector2(0, 0)
-	var day int = 0
+	prevCamScroll := camScroll
 	transitionCounter := 0.0
-	overlays := []rl.Color{
-		rl.NewColor(255, 255, 255, 0),
-		rl.NewColor(247, 228, 160, 30),
-		rl.NewColor(255, 151, 89, 80),
-		rl.NewColor(70, 63, 103, 100),
-		rl.NewColor(4, 26, 54, 150),
-		rl.NewColor(84, 88, 131, 80),
-	}
-	overlayIdx := 1
-	overlayColor := overlays[0]
-	overlayCounter := 0
 
-	for !rl.WindowShouldClose() {
-		playerMoveX := []float32{0, 0}
-		playerMoveY := []float32{0, 0}
-		dt := rl.GetFrameTime()
+	// step advances the game by one fixed timestep
+	step := func(dt float32, in Input) {
+		movement := rl.NewVector2(0, 0)
+		prevCamScroll = camScroll
+		playtime += float64(dt)
 
 		if transitionCounter > 0 {
 			transitionCounter = math.Max(0, transitionCounter-200.0*float64(dt))
+			fishing.Stop(&player)
+		} else if fishing.Active() {
+			fishing.Update(dt, in.ChargeTool, in.UseItem, &player, game)
 		} else if showInventory {
-			if rl.IsKeyPressed(rl.KeyI) {
+			if in.ToggleInventory {
 				showInventory = false
-			} else if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
-				inventoryUI.ItemClick(&playerInventory, rl.GetMousePosition())
+			} else if in.Click {
+				inventoryUI.ItemClick(&game.Inventory, in.Mouse)
+			} else if in.Slot > 0 {
+				game.Hotbar.Assign(in.Slot-1, inventoryUI.Selected)
 			}
-			inventoryUI.ItemHover(&playerInventory, rl.GetMousePosition())
-		} else if showShop {
-			if rl.IsKeyPressed(rl.KeySpace) {
-				showShop = false
+			inventoryUI.ItemHover(&game.Inventory, in.Mouse)
+		} else if openShop != nil {
+			if in.Interact {
+				openShop = nil
 
 			} else {
-				if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
-					seedShopUI.Click(rl.GetMousePosition(), &playerInventory, &seedShop)
+				if in.Click {
+					action := shopUI.Click(in.Mouse, &game.Inventory, openShop)
+					var err error
+					switch {
+					case action.Kind == ui.ShopBuy && openShop == &game.Blacksmith:
+						err = game.Upgrade(action.Item)
+					case action.Kind == ui.ShopBuy:
+						err = game.Buy(action.Item, action.Quantity)
+					case action.Kind == ui.ShopSell:
+						err = game.Sell(action.Item, action.Quality, action.Quantity)
+					}
+					if err != nil {
+						log.Printf("trade failed: %v", err)
+					}
 				}
-				seedShopUI.ItemHover(rl.GetMousePosition(), &playerInventory, &seedShop)
-				seedShopUI.Update()
+				shopUI.ItemHover(in.Mouse, &game.Inventory, openShop)
+				shopUI.Update(dt, &game.Inventory, openShop)
 			}
 		} else {
-			if rl.IsKeyDown(rl.KeyUp) {
-				playerMoveY[0] = 1
-			}
-			if rl.IsKeyDown(rl.KeyDown) {
-				playerMoveY[1] = 1
-			}
-			if rl.IsKeyDown(rl.KeyLeft) {
-				playerMoveX[0] = 1
-			}
-			if rl.IsKeyDown(rl.KeyRight) {
-				playerMoveX[1] = 1
-			}
+			movement = in.Movement()
 
-			if rl.IsKeyUp(rl.KeyUp) {
-				playerMoveY[0] = 0
-			}
-			if rl.IsKeyUp(rl.KeyDown) {
-				playerMoveY[1] = 0
-			}
-			if rl.IsKeyUp(rl.KeyLeft) {
-				playerMoveX[0] = 0
-			}
-			if rl.IsKeyUp(rl.KeyRight) {
-				playerMoveX[1] = 0
+			// the selection stays while a tool swings
+			if player.ToolCounter == 0 {
+				if in.Slot > 0 {
+					game.Hotbar.Select(in.Slot - 1)
+				}
+				game.Hotbar.Scroll(in.Scroll)
 			}
-
-			if rl.IsKeyPressed(rl.KeyS) {
-				player.SwitchTool()
-			} else if rl.IsKeyPressed(rl.KeyC) && player.ToolCounter == 0 {
+			selected, _ := game.Hotbar.SelectedItem(&game.Inventory)
+			player.SetTool(selected.Tool)
+			tier := game.ToolTier(player.Tool)
+			if in.UseItem && player.ToolCounter == 0 && selected.Type == "tool" {
+				level := player.ReleaseCharge(tier)
+				area := toolArea(&player, tm.Tilesize, level)
+				// the rules of the farm decide which cells of the swing are dug or watered
 				if player.Tool == "shovel" {
-					hp := player.ToolHitPoint()
-					rects := tm.GetFarmRectsAround(hp)
-					idx := slices.IndexFunc(rects, func(r rl.Rectangle) bool {
-						return rl.CheckCollisionCircleRec(hp, 5, r)
-					})
-					if idx != -1 {
-						r := rects[idx]
-						p := world.GetCellPos(rl.NewVector2(r.X, r.Y), float64(tm.Tilesize))
-						if ft, ok := tm.FarmTiles[p]; ok && ft.State == "empty" {
-							player.UseTool(100)
-						}
+					if game.UseTool(player.Tool, level) == nil {
+						player.UseTool(100)
+						digCells = area
 					}
 				} else if player.Tool == "water" {
-					hp := player.ToolHitPoint()
-					rects := tm.GetFarmRectsAround(hp)
-					idx := slices.IndexFunc(rects, func(r rl.Rectangle) bool {
-						return rl.CheckCollisionCircleRec(hp, 5, r)
-					})
-					if idx != -1 {
+					if game.RefillCan(hitCell(&player, tm.Tilesize)) == nil {
 						player.UseTool(100)
-						tm.AddWetTile(player.ToolHitPoint())
+					} else if game.UseTool(player.Tool, level) == nil {
+						player.UseTool(100)
+						for _, c := range game.Pour(area) {
+							session.Act(lan.ToolUse{Action: lan.ActionWater, Cell: sim.CellPosition(c)}, game)
+						}
 					}
 				} else if player.Tool == "axe" {
 					hp := player.ToolHitPoint()
-					if idx := GetCollidedTreeIdx(tm.Trees, hp); idx != -1 && tm.Trees[idx].State == "idle" {
-						var duration float32 = 500
-						player.UseTool(duration)
-						tree := tm.Trees[idx]
-						tree.Shake(duration)
-						tm.Trees[idx] = tree
-						playerInventory.Increase("Wood", tree.WoodCount)
+					if idx := GetCollidedTreeIdx(tm.Trees, hp); idx != -1 && tm.Trees[idx].CanChop() && game.UseTool(player.Tool, 0) == nil {
+						player.UseTool(swingDuration)
+						session.Act(lan.ToolUse{Action: lan.ActionAxe, Cell: cellPosition(tm.TreeCell(tm.Trees[idx]))}, game)
 					}
-				}
-			}
-			if rl.IsKeyPressed(rl.KeyD) {
-				seeds := playerInventory.AvailableSeeds()
-				if idx := slices.Index(seeds, currentSeed); idx != -1 {
-					idx = (idx + 1) % len(seeds)
-					currentSeed = seeds[idx]
-				}
-			}
-			if rl.IsKeyPressed(rl.KeyX) {
-				hp := player.ToolHitPoint()
-				rects := tm.GetFarmRectsAround(hp)
-				idx := slices.IndexFunc(rects, func(r rl.Rectangle) bool {
-					return rl.CheckCollisionCircleRec(hp, 5, r)
-				})
-				if idx != -1 {
-					cp := world.GetCellPos(rl.NewVector2(rects[idx].X, rects[idx].Y), float64(tm.Tilesize))
-					if ft, ok := tm.FarmTiles[cp]; ok && currentSeed != "" && ft.State == "digged" && playerInventory.Count(items.CropToSeedName(currentSeed)) > 0 {
-						ft.State = currentSeed
-						tm.FarmTiles[cp] = ft
-						if q := playerInventory.Decrease(items.CropToSeedName(currentSeed), 1); q >= 0 {
-							seeds := playerInventory.AvailableSeeds()
-							if len(seeds) > 0 {
-								currentSeed = playerInventory.AvailableSeeds()[0]
-							} else {
-								currentSeed = ""
-							}
-						}
+				} else if player.Tool == "pickaxe" {
+					hp := player.ToolHitPoint()
+					if idx := GetCollidedRockIdx(tm.Rocks, hp); idx != -1 && tm.Rocks[idx].State == "idle" && game.UseTool(player.Tool, 0) == nil {
+						player.UseTool(mineDuration)
+						session.Act(lan.ToolUse{Action: lan.ActionPickaxe, Cell: cellPosition(tm.Rocks[idx].Cell)}, game)
+					}
+				} else if player.Tool == "rod" {
+					cell := simCell(world.GetCellPos(CastPoint(&player), float64(tm.Tilesize)))
+					if location := game.Farm.WaterAt(cell); location != "" && game.UseTool(player.Tool, 0) == nil {
+						fishing.Cast(location, cell, &player)
 					}
 				}
+			} else if in.UseItem && player.ToolCounter == 0 {
+				cell := hitCell(&player, tm.Tilesize)
+				switch selected.Type {
+				case "seed":
+					session.Act(lan.ToolUse{Action: lan.ActionPlant, Cell: sim.CellPosition(cell), Crop: selected.Crop}, game)
+				case "fertilizer":
+					session.Act(lan.ToolUse{Action: lan.ActionFertilize, Cell: sim.CellPosition(cell), Item: selected.ID}, game)
+				case "sapling":
+					session.Act(lan.ToolUse{Action: lan.ActionPlantTree, Cell: sim.CellPosition(sim.TreeRoot(cell)), Item: selected.ID}, game)
+				}
+			} else if in.ChargeTool && tier > 0 {
+				// the player stands still while charging
+				movement = rl.NewVector2(0, 0)
+				player.ChargeTool(dt)
 			}
-			if rl.IsKeyPressed(rl.KeySpace) {
+			if in.Interact {
 				hp := player.ToolHitPoint()
 				chp := world.GetCellPos(hp, float64(tm.Tilesize))
-				if ft, ok := GetFullyGrownCrop(chp, tm.FarmTiles, cropAssets); ok {
-					playerInventory.Increase(items.CropToCropName(ft.State), 1)
-					ft.State = "digged"
-					ft.CropAge = 0
-					tm.FarmTiles[ft.Pos] = ft
+				if game.Farm.IsGrown(simCell(chp)) {
+					session.Act(lan.ToolUse{Action: lan.ActionHarvest, Cell: cellPosition(chp)}, game)
 					// TODO add sfx for harvest
 
-				} else if _, ok := tm.Beds[chp]; ok {
-					day += 1
+				} else if game.IsBed(simCell(chp)) && !session.IsClient() {
 					// start transition. block all inputs
 					transitionCounter = 512
-					// add plant age if soil is wet, reset soil to dry
-					for p, ft := range tm.FarmTiles {
-						if ft.IsWet {
-							ft.CropAge = ft.CropAge + 1
-						}
-						ft.IsWet = false
-						tm.FarmTiles[p] = ft
-					}
+					game.Sleep()
+					session.NewDay(game, false)
+					saveGame()
+				} else if game.IsBed(simCell(chp)) {
+					session.Sleep()
 				} else if rl.CheckCollisionPointRec(hp, tm.SeedShop.Rect) {
-					showShop = true
+					openShop = &game.Shop
+					shopUI = &seedShopUI
+				} else if rl.CheckCollisionPointRec(hp, tm.Blacksmith.Rect) {
+					openShop = &game.Blacksmith
+					shopUI = &blacksmithUI
 				}
 			}
-			if rl.IsKeyPressed(rl.KeyI) {
+			if in.ToggleInventory {
 				showInventory = !showInventory
 			}
-			overlayCounter += 1
-			if overlayCounter >= 30 {
-				overlayCounter = 0
-				destColor := overlays[overlayIdx]
-				diffR := int(destColor.R) - int(overlayColor.R)
-				diffG := int(destColor.G) - int(overlayColor.G)
-				diffB := int(destColor.B) - int(overlayColor.B)
-				diffA := int(destColor.A) - int(overlayColor.A)
-				if diffR != 0 {
-					overlayColor.R = uint8(int(overlayColor.R) + diffR/int(math.Abs(float64(diffR))))
-				}
-				if diffG != 0 {
-					overlayColor.G = uint8(int(overlayColor.G) + diffG/int(math.Abs(float64(diffG))))
-				}
-				if diffB != 0 {
-					overlayColor.B = uint8(int(overlayColor.B) + diffB/int(math.Abs(float64(diffB))))
-				}
-				if diffA != 0 {
-					overlayColor.A = uint8(int(overlayColor.A) + diffA/int(math.Abs(float64(diffA))))
-				}
-				if diffR == 0 && diffG == 0 && diffB == 0 && diffA == 0 {
-					overlayIdx = (overlayIdx + 1) % len(overlays)
-				}
-			}
+		}
+		// clients wait for the host to end the day
+		if game.Tick(dt) && transitionCounter == 0 && !session.IsClient() {
+			transitionCounter = 512
+			game.PassOut()
+			session.NewDay(game, true)
+			saveGame()
 		}
 
 		camScrollDest := rl.NewVector2(player.Pos.X-WIDTH/2, player.Pos.Y-HEIGHT/2)
@@ -841,34 +1360,100 @@ func main() {
 
 		camScroll.X += dCamScroll.X * dt
 		camScroll.Y += dCamScroll.Y * dt
-		player.Update(dt, rl.NewVector2(playerMoveX[1]-playerMoveX[0], playerMoveY[1]-playerMoveY[0]), tm.GetObstaclesAround, tm.AddFarmHole)
+		player.Tired = game.IsTired()
+		player.Update(dt, movement, tm.GetObstaclesAround, func() {
+			for _, c := range digCells {
+				session.Act(lan.ToolUse{Action: lan.ActionDig, Cell: sim.CellPosition(c)}, game)
+			}
+			digCells = nil
+		})
+		if session.Update(dt, &player, &tm, game) {
+			transitionCounter = 512
+			// a client went to bed on this host
+			if !session.IsClient() {
+				saveGame()
+			}
+		}
+		tm.SyncTrees(game.Farm.Trees, chopDuration)
+		for ; treeSprites < len(tm.Trees); treeSprites++ {
+			depthRenderer.Add(TreeSprite(&tm, treeSprites))
+		}
 		for i, t := range tm.Trees {
-			prevState := t.State
 			t.Update(dt)
 			tm.Trees[i] = t
-			if prevState == "shaking" && t.State == "dead" {
-				woodDropSfx.Start(
-					rl.NewVector2(t.Pos.X+t.Size.X/2, t.Pos.Y+t.Size.Y/2),
-					7,
-					rl.Vector2Normalize(rl.NewVector2(rand.Float32()*2-1, rand.Float32()*2-1)),
-				)
+		}
+		tm.SyncRocks(game.Farm.Rocks, mineDuration)
+		for i, r := range tm.Rocks {
+			r.Update(dt)
+			tm.Rocks[i] = r
+		}
+		pendingGains = slices.DeleteFunc(pendingGains, func(change lan.InventoryChange) bool {
+			pos, busy := dropPos(&tm, change.Cell)
+			if !busy {
+				drops.Spawn(change.Item, items.Quality(change.Quality), change.Delta, itemImages[change.Item], pos, rng)
 			}
+			return !busy
+		})
+		for _, d := range drops.Update(dt, player.Center()) {
+			game.Inventory.Add(d.Item, d.Quality, 1)
 		}
-		woodDropSfx.Update(dt)
+		game.Hotbar.Sync(&game.Inventory)
+		fishing.UpdateMessage(dt)
+		weatherSfx.Update(dt, game.Weather)
 		depthRenderer.Update()
 		tm.SeedShop.Update(dt)
+		tm.Blacksmith.Update(dt)
 		for i, s := range tm.ChimneySmokeList {
 			s.Update(dt)
 			tm.ChimneySmokeList[i] = s
 		}
+	}
+
+	accumulator := float32(0)
+	input := Input{}
+	for !rl.WindowShouldClose() {
+		// cap the catch up after a stall, e.g. while the window is dragged
+		accumulator += min(rl.GetFrameTime(), maxFrameTime)
+		if playback == nil {
+			input = PollInput(input)
+		}
+		for accumulator >= fixedDt {
+			if playback != nil {
+				if frame, ok := playback.Next(); ok {
+					input = frame
+				} else {
+					log.Println("replay finished, back to live input")
+					playback = nil
+					input = PollInput(Input{})
+				}
+			}
+			if recorder != nil {
+				if err := recorder.Write(input); err != nil {
+					log.Printf("recording stopped: %v", err)
+					recorder.Close()
+					recorder = nil
+				}
+			}
+			step(fixedDt, input)
+			input = input.Consume()
+			accumulator -= fixedDt
+		}
+		// draw between the last two steps so motion stays smooth at any frame rate
+		alpha := accumulator / fixedDt
+		view := rl.Vector2Lerp(prevCamScroll, camScroll, alpha)
+		playerShift = rl.Vector2Subtract(player.Lerp(alpha), player.Pos)
 
 		rl.BeginDrawing()
 		rl.ClearBackground(rl.White)
-		tm.DrawTerrain(camScroll, rl.NewVector2(WIDTH, HEIGHT))
-		tm.DrawFarmTiles(camScroll)
+		tm.DrawTerrain(view, rl.NewVector2(WIDTH, HEIGHT))
+		tm.DrawFarmTiles(view, &game.Farm)
+		if level := player.ChargeLevel(game.ToolTier(player.Tool)); level > 0 {
+			DrawToolArea(toolArea(&player, tm.Tilesize, level), view, float32(tm.Tilesize))
+		}
+		drops.Draw(view, float32(tm.TileScale))
 
 		for _, t := range tm.GetTiles(tm.Objects, []string{"house_walls"}) {
-			tm.DrawTile(t, camScroll)
+			tm.DrawTile(t, view)
 		}
 
 		idx := slices.IndexFunc(tm.TileLayers, func(l map[rl.Vector2]Tile) bool {
@@ -884,64 +1469,134 @@ func main() {
 
 		if !inHouse {
 			for _, t := range tm.GetFloatingRoofs() {
-				tm.DrawTile(t, camScroll)
+				tm.DrawTile(t, view)
 			}
 		}
 
-		depthRenderer.Draw(camScroll, true)
+		depthRenderer.Draw(view, true)
 		if player.ToolCounter > 0 {
-			player.DrawTool(camScroll)
+			player.DrawTool(rl.Vector2Subtract(view, playerShift))
 		}
 
 		if !inHouse {
-			tm.DrawRoof(camScroll)
+			tm.DrawRoof(view)
 		}
 		for _, s := range tm.ChimneySmokeList {
-			s.Draw(camScroll)
+			s.Draw(view)
 		}
+		fishing.Draw(&player, rl.Vector2Subtract(view, playerShift), uiAssets, cropAssets["fish"].Img, float32(tm.TileScale))
 
 		// draw ui
-		rl.DrawRectangle(0, 0, WIDTH, HEIGHT, overlayColor)
-
-		rl.DrawText(fmt.Sprintf("Day %d", day), 10, 10, 32, rl.White)
-		if currentSeed != "" {
-			rl.DrawTexturePro(
-				tm.tilesetAsset,
-				tm.GetSrcRect(getFullCropTileId(currentSeed)),
-				rl.NewRectangle(seedUiPos.X, seedUiPos.Y, float32(tm.Tilesize), float32(tm.Tilesize)),
-				rl.NewVector2(0, 0),
-				0,
-				rl.White,
-			)
+		rl.DrawRectangle(0, 0, WIDTH, HEIGHT, DaylightOverlay(game.Clock))
+		if !inHouse {
+			weatherSfx.Draw()
 		}
 
-		toolTex := toolsUIAsset[player.Tool]
-		DrawTextureCenterV(toolTex, rl.NewVector2(float32(tm.Tilesize)*2, HEIGHT-80), float32(tm.Tilesize), float32(tm.TileScale))
+		rl.DrawText(game.Date().String(), 10, 10, 32, rl.White)
+		rl.DrawText(game.Clock.String(), 10, 46, 28, rl.White)
+		rl.DrawText(fmt.Sprintf("%s, tomorrow %s", game.Weather, game.Forecast), 10, 78, 20, rl.White)
+		DrawEnergyBar(uiAssets, game.Energy, game.IsTired(), rl.NewVector2(WIDTH-20, HEIGHT-20), float32(tm.TileScale))
+
+		hotbarUI.Draw(&game.Hotbar, &game.Inventory, uiAssets, float32(tm.TileScale))
+		if player.Tool == "water" {
+			slot := hotbarUI.SlotRect(game.Hotbar.Selected)
+			DrawWaterBar(uiAssets, game.WaterCharges, rl.NewVector2(slot.X+slot.Width*0.5, slot.Y-56), float32(tm.TileScale))
+		}
+		fishing.DrawMessage(rl.NewVector2(WIDTH/2, HEIGHT-140))
 		if transitionCounter > 256 {
 			rl.DrawRectangle(0, 0, WIDTH, HEIGHT, rl.NewColor(0, 0, 0, uint8(512-transitionCounter)))
 		} else if transitionCounter > 0 {
 			rl.DrawRectangle(0, 0, WIDTH, HEIGHT, rl.NewColor(0, 0, 0, uint8(transitionCounter)))
 		}
 
-		if showShop {
-			seedShopUI.Draw(&seedShop, &playerInventory, uiAssets, float32(tm.TileScale))
+		if openShop != nil {
+			shopUI.Draw(openShop, &game.Inventory, uiAssets, float32(tm.TileScale))
 		}
-		woodDropSfx.Draw(camScroll, float32(tm.TileScale))
 		// draw inventory
 		if showInventory {
-			inventoryUI.Draw(&playerInventory, uiAssets, float32(tm.TileScale))
+			inventoryUI.Draw(&game.Inventory, uiAssets, float32(tm.TileScale))
 		}
 		rl.EndDrawing()
 	}
+	saveGame()
 }
 
-func GetFullyGrownCrop(cellpos rl.Vector2, farmTiles map[rl.Vector2]FarmTile, cropAssets map[string]strip.StripImg) (FarmTile, bool) {
-	if ft, ok := farmTiles[cellpos]; ok {
-		if ca, ok := cropAssets[ft.State]; ok && ft.CropAge >= ca.StripCount {
-			return ft, true
-		}
+func cellPosition(cellpos rl.Vector2) save.Position {
+	return save.Position{X: cellpos.X, Y: cellpos.Y}
+}
+
+// frames of the greenbar and redbar sprites go from empty 00 to full 06
+const energyBarFrames = 6
+
+// DrawEnergyBar draws the energy bar with its bottom right corner at pos, red once the player is tired
+func DrawEnergyBar(uiAssets map[string]rl.Texture2D, energy float32, tired bool, pos rl.Vector2, scale float32) {
+	bar := "greenbar"
+	if tired {
+		bar = "redbar"
 	}
-	return FarmTile{}, false
+	frame := int(math.Ceil(float64(energy / sim.MaxEnergy * energyBarFrames)))
+	tex := uiAssets[fmt.Sprintf("%s_%02d", bar, frame)]
+	size := rl.NewVector2(float32(tex.Width)*scale, float32(tex.Height)*scale)
+	rl.DrawTextureEx(tex, rl.NewVector2(pos.X-size.X, pos.Y-size.Y), 0, scale, rl.White)
+	rl.DrawText("Energy", int32(pos.X-size.X), int32(pos.Y-size.Y)-24, 20, rl.White)
+}
+
+// frames of the bluebar sprites go from empty 00 to full 05
+const waterBarFrames = 5
+
+// DrawWaterBar draws the water left in the watering can centered under the tool at pos
+func DrawWaterBar(uiAssets map[string]rl.Texture2D, charges int, pos rl.Vector2, scale float32) {
+	frame := int(math.Ceil(float64(charges) / sim.MaxWaterCharges * waterBarFrames))
+	tex := uiAssets[fmt.Sprintf("bluebar_%02d", frame)]
+	size := rl.NewVector2(float32(tex.Width)*scale, float32(tex.Height)*scale)
+	rl.DrawTextureEx(tex, rl.NewVector2(pos.X-size.X*0.5, pos.Y), 0, scale, rl.White)
+	text := fmt.Sprintf("%d/%d", charges, sim.MaxWaterCharges)
+	rl.DrawText(text, int32(pos.X)-rl.MeasureText(text, 20)/2, int32(pos.Y+size.Y)+4, 20, rl.White)
+}
+
+// dropPos returns where the items gained on cell drop, at the trunk of a tree or the center of a rock
+// rooted there and else in the middle of the cell. busy is true while the tree or rock still shakes.
+func dropPos(tm *Tilemap, cell save.Position) (rl.Vector2, bool) {
+	cellpos := rl.NewVector2(cell.X, cell.Y)
+	if idx := slices.IndexFunc(tm.Trees, func(t Tree) bool { return tm.TreeCell(t) == cellpos }); idx != -1 {
+		t := tm.Trees[idx]
+		return rl.NewVector2(t.Hitbox.X+t.Hitbox.Width/2, t.Hitbox.Y+t.Hitbox.Height/2), t.State == "shaking" || t.State == "uprooting"
+	}
+	if idx := slices.IndexFunc(tm.Rocks, func(r Rock) bool { return r.Cell == cellpos }); idx != -1 {
+		return tm.Rocks[idx].Center, tm.Rocks[idx].State == "cracking"
+	}
+	size := float32(tm.Tilesize)
+	return rl.NewVector2((cell.X+0.5)*size, (cell.Y+0.5)*size), false
+}
+
+// hitCell returns the cell in front of the player
+func hitCell(player *entity.Player, tilesize int) sim.Cell {
+	return simCell(world.GetCellPos(player.ToolHitPoint(), float64(tilesize)))
+}
+
+// toolArea returns the cells a swing of the player charged to level hits
+func toolArea(player *entity.Player, tilesize int, level int) []sim.Cell {
+	dx := 1
+	if player.Flipped {
+		dx = -1
+	}
+	return sim.ToolArea(hitCell(player, tilesize), dx, level)
+}
+
+// DrawToolArea outlines the cells a charged swing will hit
+func DrawToolArea(cells []sim.Cell, offset rl.Vector2, tilesize float32) {
+	for _, c := range cells {
+		pos := rl.Vector2Subtract(rl.Vector2Scale(simCellPos(c), tilesize), offset)
+		rl.DrawRectangleLinesEx(rl.NewRectangle(pos.X, pos.Y, tilesize, tilesize), 2, rl.NewColor(255, 255, 255, 160))
+	}
+}
+
+func simCell(cellpos rl.Vector2) sim.Cell {
+	return sim.Cell{X: int(cellpos.X), Y: int(cellpos.Y)}
+}
+
+func simCellPos(c sim.Cell) rl.Vector2 {
+	return rl.NewVector2(float32(c.X), float32(c.Y))
 }
 
 func DrawTextureCenterV(tex rl.Texture2D, pos rl.Vector2, tilesize float32, tilescale float32) {
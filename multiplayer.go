@@ -0,0 +1,355 @@
+package main
+
+import (
+	"log"
+	"net"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/theanzy/farmsim/internal/calendar"
+	"github.com/theanzy/farmsim/internal/entity"
+	"github.com/theanzy/farmsim/internal/items"
+	"github.com/theanzy/farmsim/internal/lan"
+	"github.com/theanzy/farmsim/internal/render"
+	"github.com/theanzy/farmsim/internal/server"
+	"github.com/theanzy/farmsim/internal/sim"
+)
+
+const (
+	chopDuration float32 = 500
+	mineDuration float32 = 200
+	// one swing of the axe, trees and stumps take a few of them
+	swingDuration float32 = 100
+)
+
+// how often the local player position is sent, in seconds
+const moveSendInterval float32 = 0.05
+
+// Session routes farm changes of the local player. Playing solo or hosting applies them
+// to the local game, a client sends them to the host and waits for the farm update.
+type Session struct {
+	Host      *lan.Host
+	Announcer *lan.Announcer
+	FarmName  string
+	Client    *lan.Client
+	PlayerID  int
+	Style     string
+	Remotes   map[int]*entity.RemotePlayer
+	newRemote func(id int, style string) entity.RemotePlayer
+	renderer  *render.DepthRenderer
+	spriteIDs map[int]int
+	sendTimer float32
+	// receives the items tool uses of the local player gave, they drop on the ground first.
+	// Without it they go straight to the inventory.
+	Gain func(change lan.InventoryChange)
+}
+
+// remote players are registered with renderer so they depth sort with the rest of the world
+func NewSession(newRemote func(id int, style string) entity.RemotePlayer, renderer *render.DepthRenderer, style string) Session {
+	return Session{
+		PlayerID:  lan.HostPlayerID,
+		Style:     style,
+		Remotes:   map[int]*entity.RemotePlayer{},
+		newRemote: newRemote,
+		renderer:  renderer,
+		spriteIDs: map[int]int{},
+	}
+}
+
+func (s *Session) IsClient() bool {
+	return s.Client != nil
+}
+
+func (s *Session) Close() {
+	if s.Announcer != nil {
+		s.Announcer.Close()
+	}
+	if s.Host != nil {
+		s.Host.Close()
+	}
+	if s.Client != nil {
+		s.Client.Close()
+	}
+}
+
+// Announcement describes the hosted farm for LAN discovery
+func (s *Session) Announcement(day int) lan.Announcement {
+	port := lan.DefaultPort
+	if addr, ok := s.Host.Addr().(*net.TCPAddr); ok {
+		port = addr.Port
+	}
+	return lan.Announcement{
+		FarmName: s.FarmName,
+		Day:      day,
+		Players:  s.Host.PlayerCount(),
+		Port:     port,
+	}
+}
+
+// Act performs a tool action of the local player
+func (s *Session) Act(use lan.ToolUse, game *sim.Game) {
+	use.PlayerID = s.PlayerID
+	if s.IsClient() {
+		s.send(lan.MsgToolUse, use)
+		return
+	}
+	change, err := server.ApplyToolUse(game, &game.Inventory, use)
+	if err != nil {
+		return
+	}
+	s.applyInventoryChange(&game.Inventory, change)
+	s.broadcastFarm(&game.Farm, use)
+}
+
+// NewDay tells clients that the host slept or passed out and the whole farm changed
+func (s *Session) NewDay(game *sim.Game, passedOut bool) {
+	if s.Host == nil {
+		return
+	}
+	for _, m := range server.NewDay(game, passedOut) {
+		s.Host.Broadcast(m, lan.HostPlayerID)
+	}
+}
+
+// Sleep asks the host to end the day. A player host ends it right away like its own bed does,
+// a dedicated server waits until every player is in bed.
+func (s *Session) Sleep() {
+	if s.IsClient() {
+		s.send(lan.MsgSleep, lan.Sleep{PlayerID: s.PlayerID})
+	}
+}
+
+// Update sends the local player state and handles every message received since the last frame.
+// It returns true when the host started a new day.
+func (s *Session) Update(dt float32, player *entity.Player, tm *Tilemap, game *sim.Game) bool {
+	if s.Host == nil && s.Client == nil {
+		return false
+	}
+	s.sendTimer -= dt
+	if s.sendTimer <= 0 {
+		s.sendTimer = moveSendInterval
+		move := lan.PlayerMove{
+			PlayerID:  s.PlayerID,
+			X:         player.Pos.X,
+			Y:         player.Pos.Y,
+			AnimState: player.AnimState,
+			Flipped:   player.Flipped,
+			Tool:      player.Tool,
+			Style:     s.Style,
+		}
+		if s.IsClient() {
+			s.send(lan.MsgPlayerMove, move)
+		} else if m, err := lan.NewMessage(lan.MsgPlayerMove, move); err == nil {
+			s.Host.Broadcast(m, lan.HostPlayerID)
+		}
+	}
+
+	newDay := false
+	if s.Host != nil {
+		if s.Announcer != nil {
+			s.Announcer.SetInfo(s.Announcement(game.Day))
+		}
+		for {
+			select {
+			case in := <-s.Host.Inbox():
+				if s.handleHostMessage(in, game) {
+					newDay = true
+				}
+				continue
+			default:
+			}
+			break
+		}
+	}
+	if s.Client != nil {
+		for {
+			select {
+			case m, ok := <-s.Client.Inbox():
+				if !ok {
+					log.Println("lost connection to host, continuing offline")
+					s.Client = nil
+					for id := range s.Remotes {
+						s.removeRemote(id)
+					}
+					return newDay
+				}
+				if s.handleClientMessage(m, tm, game) {
+					newDay = true
+				}
+				continue
+			default:
+			}
+			break
+		}
+	}
+	for _, p := range s.Remotes {
+		p.Update(dt)
+	}
+	return newDay
+}
+
+// handleHostMessage returns true when a client went to bed and started a new day
+func (s *Session) handleHostMessage(in lan.Incoming, game *sim.Game) bool {
+	switch in.Message.Type {
+	case lan.MsgHello:
+		hello, err := lan.Decode[lan.Hello](in.Message)
+		if err != nil {
+			log.Printf("client %d: bad hello: %v", in.ClientID, err)
+			return false
+		}
+		log.Printf("%s joined as player %d", hello.Name, in.ClientID)
+		if m, err := lan.NewMessage(lan.MsgWelcome, server.Welcome(game, in.ClientID)); err == nil {
+			s.Host.Send(in.ClientID, m)
+		}
+	case lan.MsgPlayerMove:
+		move, err := lan.Decode[lan.PlayerMove](in.Message)
+		if err != nil {
+			return false
+		}
+		// never trust the id sent by the client
+		move.PlayerID = in.ClientID
+		s.updateRemote(move)
+		if m, err := lan.NewMessage(lan.MsgPlayerMove, move); err == nil {
+			s.Host.Broadcast(m, in.ClientID)
+		}
+	case lan.MsgPlayerLeave:
+		s.removeRemote(in.ClientID)
+		if m, err := lan.NewMessage(lan.MsgPlayerLeave, lan.PlayerLeave{PlayerID: in.ClientID}); err == nil {
+			s.Host.Broadcast(m, in.ClientID)
+		}
+	case lan.MsgToolUse:
+		use, err := lan.Decode[lan.ToolUse](in.Message)
+		if err != nil {
+			return false
+		}
+		use.PlayerID = in.ClientID
+		// the host trusts clients to own the seeds, fertilizer and saplings they use
+		change, err := server.ApplyToolUse(game, nil, use)
+		if err != nil {
+			return false
+		}
+		if change.Item != "" {
+			if m, err := lan.NewMessage(lan.MsgInventoryChange, change); err == nil {
+				s.Host.Send(in.ClientID, m)
+			}
+		}
+		s.broadcastFarm(&game.Farm, use)
+	case lan.MsgSleep:
+		game.Sleep()
+		s.NewDay(game, false)
+		return true
+	}
+	return false
+}
+
+func (s *Session) handleClientMessage(m lan.Message, tm *Tilemap, game *sim.Game) bool {
+	switch m.Type {
+	case lan.MsgWelcome:
+		welcome, err := lan.Decode[lan.Welcome](m)
+		if err != nil {
+			log.Printf("bad welcome: %v", err)
+			return false
+		}
+		s.PlayerID = welcome.PlayerID
+		game.SetDay(welcome.Day)
+		game.Clock = calendar.Clock(welcome.Clock)
+		game.SetWeather(welcome.Weather, welcome.Forecast)
+		game.Farm.Restore(welcome.FarmTiles, welcome.Trees)
+		game.Farm.RestoreRocks(welcome.Rocks)
+		// trees felled before joining are stumps already
+		tm.SyncTrees(game.Farm.Trees, 0)
+		tm.SyncRocks(game.Farm.Rocks, 0)
+	case lan.MsgFarmUpdate:
+		update, err := lan.Decode[lan.FarmUpdate](m)
+		if err != nil {
+			return false
+		}
+		game.Farm.Restore(update.FarmTiles, update.Trees)
+		game.Farm.RestoreRocks(update.Rocks)
+	case lan.MsgInventoryChange:
+		change, err := lan.Decode[lan.InventoryChange](m)
+		if err != nil {
+			return false
+		}
+		s.applyInventoryChange(&game.Inventory, change)
+	case lan.MsgDayUpdate:
+		update, err := lan.Decode[lan.DayUpdate](m)
+		if err != nil {
+			return false
+		}
+		game.SetDay(update.Day)
+		if update.PassedOut {
+			game.Penalize()
+		}
+		game.SetWeather(update.Weather, update.Forecast)
+		return true
+	case lan.MsgPlayerMove:
+		move, err := lan.Decode[lan.PlayerMove](m)
+		if err != nil || move.PlayerID == s.PlayerID {
+			return false
+		}
+		s.updateRemote(move)
+	case lan.MsgPlayerLeave:
+		leave, err := lan.Decode[lan.PlayerLeave](m)
+		if err != nil {
+			return false
+		}
+		s.removeRemote(leave.PlayerID)
+	}
+	return false
+}
+
+func (s *Session) updateRemote(move lan.PlayerMove) {
+	p, ok := s.Remotes[move.PlayerID]
+	if !ok {
+		remote := s.newRemote(move.PlayerID, move.Style)
+		p = &remote
+		s.Remotes[move.PlayerID] = p
+		s.spriteIDs[move.PlayerID] = s.renderer.Add(p.Sprite())
+	}
+	p.Push(entity.Snapshot{
+		Pos:       rl.NewVector2(move.X, move.Y),
+		AnimState: move.AnimState,
+		Flipped:   move.Flipped,
+		Tool:      move.Tool,
+	})
+}
+
+func (s *Session) removeRemote(id int) {
+	if spriteID, ok := s.spriteIDs[id]; ok {
+		s.renderer.Remove(spriteID)
+		delete(s.spriteIDs, id)
+	}
+	delete(s.Remotes, id)
+}
+
+func (s *Session) send(t lan.MessageType, payload any) {
+	m, err := lan.NewMessage(t, payload)
+	if err != nil {
+		log.Printf("could not encode %s: %v", t, err)
+		return
+	}
+	if err := s.Client.Send(m); err != nil {
+		log.Printf("could not send %s: %v", t, err)
+	}
+}
+
+// broadcastFarm sends the tile or tree touched by use to every client
+func (s *Session) broadcastFarm(farm *sim.Farm, use lan.ToolUse) {
+	if s.Host == nil {
+		return
+	}
+	if m, err := lan.NewMessage(lan.MsgFarmUpdate, server.FarmUpdate(farm, use)); err == nil {
+		s.Host.Broadcast(m, lan.HostPlayerID)
+	}
+}
+
+func (s *Session) applyInventoryChange(inventory *items.Inventory, change lan.InventoryChange) {
+	quality := items.Quality(change.Quality)
+	if change.Delta > 0 && s.Gain != nil {
+		s.Gain(change)
+	} else if change.Delta > 0 {
+		inventory.Add(change.Item, quality, change.Delta)
+	} else if change.Delta < 0 {
+		inventory.Remove(change.Item, quality, -change.Delta)
+	}
+}